@@ -0,0 +1,157 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sigs.k8s.io/yaml"
+)
+
+// engineCmd represents the engine command
+var engineCmd = &cobra.Command{
+	Use:     "engine",
+	Short:   "Starts, inspects and stops Bhojpur SQL engines",
+	Aliases: []string{"engines"},
+}
+
+func init() {
+	rootCmd.AddCommand(engineCmd)
+}
+
+// newClient connects to the Bhojpur SQL server. Callers must close the returned connection.
+func newClient() (v1.SqlServiceClient, io.Closer) {
+	conn := dial()
+	return v1.NewSqlServiceClient(conn), conn
+}
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// addOutputFlag registers the --output flag on cmd and validates it before cmd runs
+func addOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", outputTable, "output format: table, json or yaml")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		switch *output {
+		case outputTable, outputJSON, outputYAML:
+			return nil
+		}
+		return fmt.Errorf("unknown output format %q: must be table, json or yaml", *output)
+	}
+}
+
+// printMessage prints a message in JSON or YAML format
+func printMessage(out io.Writer, format string, msg proto.Message) error {
+	js, err := protojson.MarshalOptions{Indent: "  ", UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case outputJSON:
+		_, err = fmt.Fprintln(out, string(js))
+	case outputYAML:
+		var y []byte
+		y, err = yaml.JSONToYAML(js)
+		if err == nil {
+			_, err = out.Write(y)
+		}
+	default:
+		err = fmt.Errorf("unknown output format %q: must be table, json or yaml", format)
+	}
+	return err
+}
+
+// printEngines prints engine status as table or, together with the message they came from, as JSON or YAML
+func printEngines(out io.Writer, format string, msg proto.Message, engines ...*v1.EngineStatus) error {
+	if format != outputTable {
+		return printMessage(out, format, msg)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tOWNER\tSPEC\tPHASE\tSUCCESS\tCREATED\tFINISHED")
+	for _, e := range engines {
+		var (
+			md      = e.GetMetadata()
+			success = "-"
+		)
+		if e.Phase == v1.EnginePhase_PHASE_DONE {
+			success = fmt.Sprint(e.GetConditions().GetSuccess())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Name,
+			md.GetOwner(),
+			md.GetEngineSpecName(),
+			filterexpr.PhaseValue(e.Phase),
+			success,
+			formatTimestamp(md.GetCreated().AsTime(), md.GetCreated() != nil),
+			formatTimestamp(md.GetFinished().AsTime(), md.GetFinished() != nil),
+		)
+	}
+	return w.Flush()
+}
+
+func formatTimestamp(t time.Time, set bool) string {
+	if !set {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// parseAnnotations parses key=value pairs
+func parseAnnotations(pairs []string) ([]*v1.Annotation, error) {
+	res := make([]*v1.Annotation, 0, len(pairs))
+	for _, p := range pairs {
+		segs := strings.SplitN(p, "=", 2)
+		if len(segs) != 2 || segs[0] == "" {
+			return nil, fmt.Errorf("invalid annotation %q: expected key=value", p)
+		}
+		res = append(res, &v1.Annotation{Key: segs[0], Value: segs[1]})
+	}
+	return res, nil
+}
+
+// parseWaitUntil parses either a point in time in RFC3339 format or a duration from now, e.g. 2h30m
+func parseWaitUntil(s string) (*timestamppb.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return timestamppb.New(time.Now().Add(d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wait-until %q: must be a duration like 2h or a time like 2006-01-02T22:00:00Z", s)
+	}
+	return timestamppb.New(t), nil
+}
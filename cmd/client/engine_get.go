@@ -0,0 +1,70 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var engineGetOpts struct {
+	Output string
+}
+
+// engineGetCmd represents the engine get command
+var engineGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Retrieves the details of an engine",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, conn := newClient()
+		defer conn.Close()
+		resp, err := client.GetEngine(context.Background(), &v1.GetEngineRequest{Name: args[0]})
+		if err != nil {
+			return err
+		}
+
+		err = printEngines(os.Stdout, engineGetOpts.Output, resp.Result, resp.Result)
+		if err != nil || engineGetOpts.Output != outputTable {
+			return err
+		}
+		if d := resp.Result.Details; d != "" {
+			fmt.Printf("\ndetails: %s\n", d)
+		}
+		for _, a := range resp.Result.GetMetadata().GetAnnotations() {
+			fmt.Printf("annotation %s: %s\n", a.Key, a.Value)
+		}
+		for _, r := range resp.Result.Results {
+			fmt.Printf("result %s: %s %s\n", r.Type, r.Payload, r.Description)
+		}
+		return nil
+	},
+}
+
+func init() {
+	engineCmd.AddCommand(engineGetCmd)
+
+	addOutputFlag(engineGetCmd, &engineGetOpts.Output)
+}
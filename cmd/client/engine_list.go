@@ -0,0 +1,87 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"os"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/spf13/cobra"
+)
+
+var engineListOpts struct {
+	Filter []string
+	Order  []string
+	Start  int
+	Limit  int
+	Output string
+}
+
+// engineListCmd represents the engine list command
+var engineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists engines",
+	Long: `Lists engines known to the Bhojpur SQL server.
+
+Each --filter is a comma separated list of terms of which any must match, e.g.
+  --filter phase==running,phase==preparing --filter owner==dba
+Terms compare a field with a value using == (equals), != (does not equal),
+^= (starts with), $= (ends with) or ~= (contains). field? tests if a field exists,
+a leading ! negates a term.
+
+Engines are ordered by --order field[:asc|desc], e.g. --order created:desc`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := filterexpr.ParseFilter(engineListOpts.Filter)
+		if err != nil {
+			return err
+		}
+		order, err := filterexpr.ParseOrder(engineListOpts.Order)
+		if err != nil {
+			return err
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		resp, err := client.ListEngines(context.Background(), &v1.ListEnginesRequest{
+			Filter: filter,
+			Order:  order,
+			Start:  int32(engineListOpts.Start),
+			Limit:  int32(engineListOpts.Limit),
+		})
+		if err != nil {
+			return err
+		}
+		return printEngines(os.Stdout, engineListOpts.Output, resp, resp.Result...)
+	},
+}
+
+func init() {
+	engineCmd.AddCommand(engineListCmd)
+
+	engineListCmd.Flags().StringArrayVar(&engineListOpts.Filter, "filter", nil, "filter expression, e.g. phase==running,phase==preparing")
+	engineListCmd.Flags().StringSliceVar(&engineListOpts.Order, "order", nil, "order expressions, e.g. created:desc")
+	engineListCmd.Flags().IntVar(&engineListOpts.Start, "start", 0, "number of engines to skip")
+	engineListCmd.Flags().IntVar(&engineListOpts.Limit, "limit", 50, "maximum number of engines to list")
+	addOutputFlag(engineListCmd, &engineListOpts.Output)
+}
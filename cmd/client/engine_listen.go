@@ -0,0 +1,136 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/spf13/cobra"
+)
+
+var engineListenOpts struct {
+	Logs    string
+	Updates bool
+}
+
+// engineListenCmd represents the engine listen command
+var engineListenCmd = &cobra.Command{
+	Use:   "listen <name>",
+	Short: "Follows the log output and status of an engine until it is done",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := parseLogsMode(engineListenOpts.Logs)
+		if err != nil {
+			return err
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		return listen(context.Background(), client, args[0], mode, engineListenOpts.Updates, os.Stdout)
+	},
+}
+
+func init() {
+	engineCmd.AddCommand(engineListenCmd)
+
+	addListenFlags(engineListenCmd, &engineListenOpts.Logs, &engineListenOpts.Updates)
+}
+
+// addListenFlags registers the flags which control how engines are followed
+func addListenFlags(cmd *cobra.Command, logs *string, updates *bool) {
+	cmd.Flags().StringVar(logs, "logs", "raw", "log output mode: raw, html, unsliced or disabled")
+	cmd.Flags().BoolVar(updates, "updates", true, "print status updates")
+}
+
+func parseLogsMode(mode string) (v1.ListenRequestLogs, error) {
+	res, ok := v1.ListenRequestLogs_value["LOGS_"+strings.ToUpper(mode)]
+	if !ok {
+		return 0, fmt.Errorf("unknown log output mode %q: must be raw, html, unsliced or disabled", mode)
+	}
+	return v1.ListenRequestLogs(res), nil
+}
+
+// listen follows an engine until it is done. If updates are requested, listen
+// returns an error if the engine failed.
+func listen(ctx context.Context, client v1.SqlServiceClient, name string, logs v1.ListenRequestLogs, updates bool, out io.Writer) error {
+	stream, err := client.Listen(ctx, &v1.ListenRequest{Name: name, Logs: logs, Updates: updates})
+	if err != nil {
+		return err
+	}
+
+	var last *v1.EngineStatus
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch c := msg.Content.(type) {
+		case *v1.ListenResponse_Update:
+			if last == nil || last.Phase != c.Update.Phase {
+				fmt.Fprintf(out, "engine %s is %s\n", c.Update.Name, filterexpr.PhaseValue(c.Update.Phase))
+			}
+			last = c.Update
+		case *v1.ListenResponse_Slice:
+			printSlice(out, logs, c.Slice)
+		}
+	}
+
+	if last != nil && last.Phase == v1.EnginePhase_PHASE_DONE && !last.GetConditions().GetSuccess() {
+		return fmt.Errorf("engine %s failed: %s", name, last.Details)
+	}
+	return nil
+}
+
+func printSlice(out io.Writer, mode v1.ListenRequestLogs, evt *v1.LogSliceEvent) {
+	if mode == v1.ListenRequestLogs_LOGS_UNSLICED {
+		fmt.Fprint(out, evt.Payload)
+		return
+	}
+
+	prefix := ""
+	if evt.Name != "" {
+		prefix = "[" + evt.Name + "] "
+	}
+	switch evt.Type {
+	case v1.LogSliceType_SLICE_CONTENT:
+		fmt.Fprintf(out, "%s%s\n", prefix, evt.Payload)
+	case v1.LogSliceType_SLICE_PHASE:
+		fmt.Fprintf(out, "--- %s\n", evt.Payload)
+	case v1.LogSliceType_SLICE_DONE:
+		fmt.Fprintf(out, "%sdone\n", prefix)
+	case v1.LogSliceType_SLICE_FAIL:
+		fmt.Fprintf(out, "%sfailed: %s\n", prefix, evt.Payload)
+	case v1.LogSliceType_SLICE_ABANDONED:
+		fmt.Fprintf(out, "%sabandoned\n", prefix)
+	case v1.LogSliceType_SLICE_RESULT:
+		fmt.Fprintf(out, "result %s: %s\n", evt.Name, evt.Payload)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var engineReplayOpts struct {
+	WaitUntil string
+	Follow    bool
+	Logs      string
+	Updates   bool
+}
+
+// engineReplayCmd represents the engine replay command
+var engineReplayCmd = &cobra.Command{
+	Use:   "replay <previous-engine>",
+	Short: "Starts a new engine based on a previous one",
+	Long: `Starts a new engine with the spec, workspace and metadata of a previous one.
+The new engine carries a replay-of annotation which names the previous engine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		waitUntil, err := parseWaitUntil(engineReplayOpts.WaitUntil)
+		if err != nil {
+			return err
+		}
+		logs, err := parseLogsMode(engineReplayOpts.Logs)
+		if err != nil {
+			return err
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		resp, err := client.StartFromPreviousEngine(context.Background(), &v1.StartFromPreviousEngineRequest{
+			PreviousEngine: args[0],
+			WaitUntil:      waitUntil,
+		})
+		if err != nil {
+			return err
+		}
+		return started(client, resp.Status, engineReplayOpts.Follow, logs, engineReplayOpts.Updates)
+	},
+}
+
+func init() {
+	engineCmd.AddCommand(engineReplayCmd)
+
+	engineReplayCmd.Flags().StringVar(&engineReplayOpts.WaitUntil, "wait-until", "", "start the engine at this time (RFC3339) or after this duration, e.g. 8h")
+	engineReplayCmd.Flags().BoolVar(&engineReplayOpts.Follow, "follow", false, "follow the engine until it is done")
+	addListenFlags(engineReplayCmd, &engineReplayOpts.Logs, &engineReplayOpts.Updates)
+}
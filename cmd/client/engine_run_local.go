@@ -0,0 +1,244 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/engine"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// uploadChunkSize is the maximum number of bytes sent per StartLocalEngine message
+const uploadChunkSize = 64 * 1024
+
+var engineRunLocalOpts struct {
+	Engine      string
+	Owner       string
+	Annotations []string
+	Detach      bool
+	Logs        string
+	Updates     bool
+}
+
+// engineRunLocalCmd represents the engine run-local command
+var engineRunLocalCmd = &cobra.Command{
+	Use:   "run-local",
+	Short: "Starts an engine from the working copy in the current directory",
+	Long: `Starts an engine from the working copy in the current directory.
+
+The engine YAML is the one sql/config.yaml points to with its defaultEngine, unless
+--engine names another. The working copy is uploaded without the .git directory and
+all paths listed in the .sqlignore file. Once started, the engine is followed
+until it is done.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		configYAML, err := ioutil.ReadFile(filepath.Join(wd, "sql", "config.yaml"))
+		if err != nil {
+			return fmt.Errorf("cannot read config: %w", err)
+		}
+		cfg, err := engine.ParseRepoConfig(configYAML)
+		if err != nil {
+			return err
+		}
+		enginePath := engineRunLocalOpts.Engine
+		if enginePath == "" {
+			enginePath = cfg.DefaultEngine
+		}
+		if enginePath == "" {
+			return fmt.Errorf("sql/config.yaml has no defaultEngine - use --engine to choose one")
+		}
+		engineYAML, err := ioutil.ReadFile(filepath.Join(wd, filepath.FromSlash(enginePath)))
+		if err != nil {
+			return fmt.Errorf("cannot read engine YAML: %w", err)
+		}
+		if _, err := engine.ParseSpec(engineYAML); err != nil {
+			return fmt.Errorf("%s: %w", enginePath, err)
+		}
+		ignore, err := readIgnoreList(filepath.Join(wd, engine.IgnoreFile))
+		if err != nil {
+			return err
+		}
+		annotations, err := parseAnnotations(engineRunLocalOpts.Annotations)
+		if err != nil {
+			return err
+		}
+		logs, err := parseLogsMode(engineRunLocalOpts.Logs)
+		if err != nil {
+			return err
+		}
+
+		md := &v1.EngineMetadata{
+			Owner:       engineRunLocalOpts.Owner,
+			Trigger:     v1.EngineTrigger_TRIGGER_MANUAL,
+			Annotations: annotations,
+		}
+		if engineRunLocalOpts.Engine != "" {
+			md.EngineSpecName = engineSpecName(enginePath)
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		st, err := uploadLocalEngine(context.Background(), client, md, configYAML, engineYAML, wd, ignore)
+		if err != nil {
+			return err
+		}
+		return started(client, st, !engineRunLocalOpts.Detach, logs, engineRunLocalOpts.Updates)
+	},
+}
+
+func engineSpecName(enginePath string) string {
+	base := filepath.Base(enginePath)
+	return base[:len(base)-len(filepath.Ext(base))]
+}
+
+// readIgnoreList reads the ignore file if there is one
+func readIgnoreList(fn string) (*engine.IgnoreList, error) {
+	f, err := os.Open(fn)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return engine.ParseIgnoreList(f)
+}
+
+// uploadLocalEngine streams everything StartLocalEngine needs in the order it expects
+func uploadLocalEngine(ctx context.Context, client v1.SqlServiceClient, md *v1.EngineMetadata, configYAML, engineYAML []byte, wd string, ignore *engine.IgnoreList) (*v1.EngineStatus, error) {
+	// cancelling the context aborts the upload if we fail halfway through
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.StartLocalEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the server has rejected the upload once Send returns io.EOF - CloseAndRecv tells us why
+	send := func(req *v1.StartLocalEngineRequest) (rejected bool, err error) {
+		err = stream.Send(req)
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+	sendAll := func(data []byte, wrap func([]byte) *v1.StartLocalEngineRequest) (rejected bool, err error) {
+		for len(data) > 0 {
+			n := len(data)
+			if n > uploadChunkSize {
+				n = uploadChunkSize
+			}
+			if rejected, err = send(wrap(data[:n])); rejected || err != nil {
+				return
+			}
+			data = data[n:]
+		}
+		return false, nil
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		pw.CloseWithError(engine.ArchiveWorkspace(wd, ignore, pw))
+	}()
+
+	steps := []func() (bool, error){
+		func() (bool, error) {
+			return send(&v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_Metadata{Metadata: md}})
+		},
+		func() (bool, error) {
+			return sendAll(configYAML, func(b []byte) *v1.StartLocalEngineRequest {
+				return &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ConfigYaml{ConfigYaml: b}}
+			})
+		},
+		func() (bool, error) {
+			return sendAll(engineYAML, func(b []byte) *v1.StartLocalEngineRequest {
+				return &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_EngineYaml{EngineYaml: b}}
+			})
+		},
+		func() (bool, error) {
+			var (
+				buf  = make([]byte, uploadChunkSize)
+				size int
+			)
+			for {
+				n, err := io.ReadFull(pr, buf)
+				if n > 0 {
+					size += n
+					rejected, serr := send(&v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ApplicationTar{
+						ApplicationTar: append([]byte(nil), buf[:n]...),
+					}})
+					if rejected || serr != nil {
+						return rejected, serr
+					}
+				}
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					break
+				}
+				if err != nil {
+					return false, fmt.Errorf("cannot archive working copy: %w", err)
+				}
+			}
+			log.WithField("bytes", size).Debug("uploaded working copy")
+			return false, nil
+		},
+		func() (bool, error) {
+			return send(&v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ApplicationTarDone{ApplicationTarDone: true}})
+		},
+	}
+	for _, step := range steps {
+		rejected, err := step()
+		if err != nil {
+			return nil, err
+		}
+		if rejected {
+			break
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+func init() {
+	engineCmd.AddCommand(engineRunLocalCmd)
+
+	engineRunLocalCmd.Flags().StringVar(&engineRunLocalOpts.Engine, "engine", "", "engine YAML to start, relative to the current directory (defaults to the defaultEngine of sql/config.yaml)")
+	engineRunLocalCmd.Flags().StringVar(&engineRunLocalOpts.Owner, "owner", os.Getenv("USER"), "owner of the engine (defaults to USER env var)")
+	engineRunLocalCmd.Flags().StringArrayVarP(&engineRunLocalOpts.Annotations, "annotation", "a", nil, "annotation of the engine in key=value form")
+	engineRunLocalCmd.Flags().BoolVar(&engineRunLocalOpts.Detach, "detach", false, "do not follow the engine once it is started")
+	addListenFlags(engineRunLocalCmd, &engineRunLocalOpts.Logs, &engineRunLocalOpts.Updates)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var engineStartOpts struct {
+	File        string
+	Owner       string
+	NameSuffix  string
+	Annotations []string
+	WaitUntil   string
+	Follow      bool
+	Logs        string
+	Updates     bool
+}
+
+// engineStartCmd represents the engine start command
+var engineStartCmd = &cobra.Command{
+	Use:   "start [<engine-path>]",
+	Short: "Starts a new engine",
+	Long: `Starts a new engine.
+
+The engine is either described by a local engine YAML file (--file), or by
+an engine path which the server resolves against its spec directory.
+With --wait-until, the engine waits until the given time before it runs.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &v1.StartEngineRequest{NameSuffix: engineStartOpts.NameSuffix}
+		if len(args) > 0 {
+			req.EnginePath = args[0]
+		}
+		if fn := engineStartOpts.File; fn != "" {
+			var err error
+			req.EngineYaml, err = ioutil.ReadFile(fn)
+			if err != nil {
+				return fmt.Errorf("cannot read engine YAML: %w", err)
+			}
+		}
+		if req.EnginePath == "" && len(req.EngineYaml) == 0 {
+			return fmt.Errorf("either an engine path or --file is required")
+		}
+		annotations, err := parseAnnotations(engineStartOpts.Annotations)
+		if err != nil {
+			return err
+		}
+		req.Metadata = &v1.EngineMetadata{
+			Owner:       engineStartOpts.Owner,
+			Trigger:     v1.EngineTrigger_TRIGGER_MANUAL,
+			Annotations: annotations,
+		}
+		req.WaitUntil, err = parseWaitUntil(engineStartOpts.WaitUntil)
+		if err != nil {
+			return err
+		}
+		logs, err := parseLogsMode(engineStartOpts.Logs)
+		if err != nil {
+			return err
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		resp, err := client.StartEngine(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		return started(client, resp.Status, engineStartOpts.Follow, logs, engineStartOpts.Updates)
+	},
+}
+
+// started reports a newly started engine and follows it if requested
+func started(client v1.SqlServiceClient, st *v1.EngineStatus, follow bool, logs v1.ListenRequestLogs, updates bool) error {
+	fmt.Printf("started %s\n", st.Name)
+	if !follow {
+		return nil
+	}
+	return listen(context.Background(), client, st.Name, logs, updates, os.Stdout)
+}
+
+func init() {
+	engineCmd.AddCommand(engineStartCmd)
+
+	engineStartCmd.Flags().StringVarP(&engineStartOpts.File, "file", "f", "", "local engine YAML file to start")
+	engineStartCmd.Flags().StringVar(&engineStartOpts.Owner, "owner", os.Getenv("USER"), "owner of the engine (defaults to USER env var)")
+	engineStartCmd.Flags().StringVar(&engineStartOpts.NameSuffix, "name-suffix", "", "suffix appended to the engine name")
+	engineStartCmd.Flags().StringArrayVarP(&engineStartOpts.Annotations, "annotation", "a", nil, "annotation of the engine in key=value form")
+	engineStartCmd.Flags().StringVar(&engineStartOpts.WaitUntil, "wait-until", "", "start the engine at this time (RFC3339) or after this duration, e.g. 8h")
+	engineStartCmd.Flags().BoolVar(&engineStartOpts.Follow, "follow", false, "follow the engine until it is done")
+	addListenFlags(engineStartCmd, &engineStartOpts.Logs, &engineStartOpts.Updates)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/spf13/cobra"
+)
+
+var engineSubscribeOpts struct {
+	Filter []string
+	Output string
+}
+
+// engineSubscribeCmd represents the engine subscribe command
+var engineSubscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Short: "Prints engine status updates as they happen",
+	Long: `Prints engine status updates as they happen.
+
+Updates can be filtered using the same --filter expressions engine list supports.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := filterexpr.ParseFilter(engineSubscribeOpts.Filter)
+		if err != nil {
+			return err
+		}
+
+		client, conn := newClient()
+		defer conn.Close()
+		stream, err := client.Subscribe(context.Background(), &v1.SubscribeRequest{Filter: filter})
+		if err != nil {
+			return err
+		}
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			st := msg.Result
+			switch engineSubscribeOpts.Output {
+			case outputTable:
+				fmt.Printf("%s  %s  %s\n", time.Now().Format(time.RFC3339), st.Name, filterexpr.PhaseValue(st.Phase))
+			case outputYAML:
+				fmt.Println("---")
+				err = printMessage(os.Stdout, outputYAML, st)
+			default:
+				err = printMessage(os.Stdout, engineSubscribeOpts.Output, st)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	},
+}
+
+func init() {
+	engineCmd.AddCommand(engineSubscribeCmd)
+
+	engineSubscribeCmd.Flags().StringArrayVar(&engineSubscribeOpts.Filter, "filter", nil, "filter expression, e.g. phase==done")
+	addOutputFlag(engineSubscribeCmd, &engineSubscribeOpts.Output)
+}
@@ -63,6 +63,9 @@ var rootCmdOpts struct {
 var rootCmd = &cobra.Command{
 	Use:   "sqlctl",
 	Short: "Bhojpur SQLclient is a client engine for relational databases",
+	// Execute reports errors itself, and usage is of no help once a command has started
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if verbose {
 			log.SetLevel(log.DebugLevel)
@@ -0,0 +1,144 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/engine"
+	"github.com/bhojpur/sql/pkg/store"
+	"github.com/bhojpur/sql/pkg/store/postgres"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var serveCmdOpts struct {
+	Port    int
+	SpecDir string
+	WorkDir string
+	DB      string
+	DSNEnv  []string
+
+	SubscriberBuffer int
+	SlowSubscriber   string
+	MaxUploadSize    int64
+	MaxWorkspaceSize int64
+	LogRetention     time.Duration
+	MaxSideload      int64
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Starts the Bhojpur SQL engine server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := engine.ParseSlowSubscriberPolicy(serveCmdOpts.SlowSubscriber)
+		if err != nil {
+			return err
+		}
+		engines, specs, numbers, err := openStores(serveCmdOpts.DB)
+		if err != nil {
+			return err
+		}
+		srv := engine.NewService(engine.Config{
+			SpecDir:              serveCmdOpts.SpecDir,
+			WorkDir:              serveCmdOpts.WorkDir,
+			SubscriberBufferSize: serveCmdOpts.SubscriberBuffer,
+			SlowSubscriberPolicy: policy,
+			MaxUploadSize:        serveCmdOpts.MaxUploadSize,
+			MaxWorkspaceSize:     serveCmdOpts.MaxWorkspaceSize,
+			LogRetention:         serveCmdOpts.LogRetention,
+			MaxStoredSideload:    serveCmdOpts.MaxSideload,
+		}, engine.SQLExecutor{DSNEnv: serveCmdOpts.DSNEnv}, engines, specs, numbers)
+		if err := srv.Recover(context.Background()); err != nil {
+			return fmt.Errorf("cannot recover engines: %w", err)
+		}
+
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", serveCmdOpts.Port))
+		if err != nil {
+			return fmt.Errorf("cannot listen on port %d: %w", serveCmdOpts.Port, err)
+		}
+
+		grpcServer := grpc.NewServer()
+		v1.RegisterSqlServiceServer(grpcServer, srv)
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigs
+			log.Info("shutting down")
+			// streams of subscribers and listeners never end on their own
+			srv.Shutdown()
+			grpcServer.GracefulStop()
+		}()
+
+		log.WithField("port", serveCmdOpts.Port).Info("Bhojpur SQL engine server is up and running")
+		return grpcServer.Serve(l)
+	},
+}
+
+// openStores connects to the PostgreSQL database at dsn and brings its schema up to date.
+// Without a dsn engines are kept in memory.
+func openStores(dsn string) (store.Engines, store.EngineSpecs, store.NumberGroup, error) {
+	if dsn == "" {
+		log.Warn("no database configured - engines are kept in memory and lost on restart")
+		return store.NewInMemoryEngineStore(), store.NewInMemoryEngineSpecStore(), store.NewInMemoryNumberGroup(), nil
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot open database: %w", err)
+	}
+	applied, err := postgres.Migrate(context.Background(), db)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(applied) > 0 {
+		log.WithField("migrations", applied).Info("applied database migrations")
+	}
+	return postgres.NewEngineStore(db), postgres.NewEngineSpecStore(db), postgres.NewNumberGroup(db), nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().IntVar(&serveCmdOpts.Port, "port", 7777, "port the gRPC API is served on")
+	serveCmd.Flags().StringVar(&serveCmdOpts.SpecDir, "spec-dir", "", "directory engine_path is resolved against")
+	serveCmd.Flags().StringVar(&serveCmdOpts.DB, "db", os.Getenv("SQL_DB"), "PostgreSQL connection string of the engine store (defaults to SQL_DB env var). Without one, engines are kept in memory.")
+	serveCmd.Flags().StringSliceVar(&serveCmdOpts.DSNEnv, "dsn-env", nil, "environment variables engine DSNs may reference as $VAR or ${VAR}, which are not expanded otherwise")
+	serveCmd.Flags().StringVar(&serveCmdOpts.WorkDir, "work-dir", "", "directory in which engine workspaces are created (defaults to the system's temp directory)")
+	serveCmd.Flags().IntVar(&serveCmdOpts.SubscriberBuffer, "subscriber-buffer", engine.DefaultSubscriberBufferSize, "number of engine updates buffered for each subscriber")
+	serveCmd.Flags().StringVar(&serveCmdOpts.SlowSubscriber, "slow-subscriber", string(engine.DropUpdates), "what happens to subscribers which cannot keep up: drop (their updates) or disconnect (them)")
+	serveCmd.Flags().Int64Var(&serveCmdOpts.MaxUploadSize, "max-upload-size", engine.DefaultMaxUploadSize, "maximum size in bytes of the gzipped working copy uploaded to start a local engine")
+	serveCmd.Flags().DurationVar(&serveCmdOpts.LogRetention, "log-retention", engine.DefaultLogRetention, "how long the logs of a finished engine are kept in memory to be listened to")
+	serveCmd.Flags().Int64Var(&serveCmdOpts.MaxSideload, "max-stored-sideload", engine.DefaultMaxStoredSideload, "maximum size in bytes of a sideload stored to replay an engine - engines with larger ones cannot be replayed")
+	serveCmd.Flags().Int64Var(&serveCmdOpts.MaxWorkspaceSize, "max-workspace-size", engine.DefaultMaxWorkspaceSize, "maximum size in bytes of an extracted engine workspace")
+}
@@ -22,7 +22,6 @@ package builder
 
 import (
 	sql2 "database/sql"
-	"fmt"
 )
 
 type optype byte
@@ -42,7 +41,9 @@ const (
 	SQLITE    = "sqlite3"
 	MYSQL     = "mysql"
 	MSSQL     = "mssql"
+	MSSQL2012 = "mssql2012" // SQL Server 2012 or later, paginating by OFFSET ... FETCH
 	ORACLE    = "oracle"
+	ORACLE12  = "oracle12" // Oracle 12c or later, paginating by OFFSET ... FETCH
 	UNION     = "union"
 	INTERSECT = "intersect"
 	EXCEPT    = "except"
@@ -66,22 +67,38 @@ type limit struct {
 // Builder describes a SQL statement
 type Builder struct {
 	optype
-	dialect    string
-	isNested   bool
-	into       string
-	from       string
-	subQuery   *Builder
-	cond       Cond
-	selects    []string
-	joins      []join
-	setOps     []setOp
-	limitation *limit
-	insertCols []string
-	insertVals []interface{}
-	updates    []UpdateCond
-	orderBy    string
-	groupBy    string
-	having     string
+	dialect  string
+	isNested bool
+	into     string
+	from     string
+	subQuery *Builder
+	cond     Cond
+	selects  []string
+	// selectExprs are selected after selects, see SelectExpr
+	selectExprs []Cond
+	joins       []join
+	setOps      []setOp
+	limitation  *limit
+	insertCols  []string
+	insertVals  []interface{}
+	insertRows  [][]interface{}
+	ctes        []cte
+	upsert      *upsert
+	returning   []string
+	maxArgs     int
+	updates     []UpdateCond
+	orderBy     []Cond
+	groupBy     []Cond
+	having      Cond
+	windows     []namedWindow
+	keyset      *keyset
+	// quoteIdents quotes table and column names according to the dialect
+	quoteIdents bool
+	// namedArgs binds sql.NamedArg arguments by their names, see NamedArgs
+	namedArgs bool
+	// limitTop and limitRowNumber are written by limitWriteTo's wrapping of the query
+	limitTop       int
+	limitRowNumber bool
 }
 
 // Dialect sets the db dialect of Builder.
@@ -115,6 +132,15 @@ func SQLite() *Builder {
 	return Dialect(SQLITE)
 }
 
+// QuoteIdents quotes the table names, column names and aliases given to From, Into, Select,
+// Insert, joins and conditions' keys according to the dialect, e.g. `order` for MySQL,
+// "order" for PostgreSQL, Oracle and SQLite or [order] for SQL Server. References like
+// table.column or alias.* are quoted part by part, expressions are written as they are.
+func (b *Builder) QuoteIdents() *Builder {
+	b.quoteIdents = true
+	return b
+}
+
 // Where sets where SQL
 func (b *Builder) Where(cond Cond) *Builder {
 	if b.cond.IsValid() {
@@ -178,7 +204,12 @@ func (b *Builder) setOperation(opType, distinctType string, cond *Builder) *Buil
 		builder = &Builder{cond: NewCond()}
 		builder.optype = setOpType
 		builder.dialect = b.dialect
+		builder.quoteIdents = b.quoteIdents
+		builder.namedArgs = b.namedArgs
 		builder.selects = b.selects
+		builder.selectExprs = b.selectExprs
+		// common table expressions precede the whole set operation
+		builder.ctes, b.ctes = b.ctes, nil
 		currentSetOps := b.setOps
 		// erase sub setOps (actually append to new Builder.unions)
 		b.setOps = nil
@@ -249,18 +280,41 @@ func (b *Builder) Delete(conds ...Cond) *Builder {
 
 // WriteTo implements Writer interface
 func (b *Builder) WriteTo(w Writer) error {
+	if dw, ok := w.(*dialectWriter); b.quoteIdents && (!ok || !dw.quote) {
+		d, err := b.requireDialect()
+		if err != nil {
+			return err
+		}
+		qw := withDialect(w, d)
+		qw.quote = true
+		w = qw
+	}
 	switch b.optype {
 	/*case condType:
 	return b.cond.WriteTo(w)*/
 	case selectType:
+		if err := b.withWriteTo(w); err != nil {
+			return err
+		}
 		return b.selectWriteTo(w)
 	case insertType:
+		// the common table expressions are written by insertWriteTo
 		return b.insertWriteTo(w)
-	case updateType:
-		return b.updateWriteTo(w)
-	case deleteType:
+	case updateType, deleteType:
+		if len(b.ctes) > 0 && !b.supports(FeatureWithUpdate) {
+			return ErrNotSupportCTE
+		}
+		if err := b.withWriteTo(w); err != nil {
+			return err
+		}
+		if b.optype == updateType {
+			return b.updateWriteTo(w)
+		}
 		return b.deleteWriteTo(w)
 	case setOpType:
+		if err := b.withWriteTo(w); err != nil {
+			return err
+		}
 		return b.setOpWriteTo(w)
 	}
 	return ErrNotSupportType
@@ -272,6 +326,9 @@ func (b *Builder) ToSQL() (string, []interface{}, error) {
 	if err := b.WriteTo(w); err != nil {
 		return "", nil, err
 	}
+	if b.namedArgs {
+		return bindNamedArgs(w.String(), w.args, GetDialect(b.dialect))
+	}
 	// in case of sql.NamedArg in args
 	for e := range w.args {
 		if namedArg, ok := w.args[e].(sql2.NamedArg); ok {
@@ -279,25 +336,13 @@ func (b *Builder) ToSQL() (string, []interface{}, error) {
 		}
 	}
 	var sql = w.String()
-	var err error
-	switch b.dialect {
-	case ORACLE, MSSQL:
-		// This is for compatibility with different sql drivers
-		for e := range w.args {
-			w.args[e] = sql2.Named(fmt.Sprintf("p%d", e+1), w.args[e])
-		}
-		var prefix string
-		if b.dialect == ORACLE {
-			prefix = ":p"
-		} else {
-			prefix = "@p"
-		}
-		if sql, err = ConvertPlaceholder(sql, prefix); err != nil {
+	if d := GetDialect(b.dialect); d != nil {
+		var err error
+		if sql, err = convertPlaceholder(sql, syntaxOf(d), d.Placeholder); err != nil {
 			return "", nil, err
 		}
-	case POSTGRES:
-		if sql, err = ConvertPlaceholder(sql, "$"); err != nil {
-			return "", nil, err
+		for e := range w.args {
+			w.args[e] = d.BindArg(e+1, w.args[e])
 		}
 	}
 	return sql, w.args, nil
@@ -309,5 +354,8 @@ func (b *Builder) ToBoundSQL() (string, error) {
 	if err := b.WriteTo(w); err != nil {
 		return "", err
 	}
+	if d := GetDialect(b.dialect); d != nil {
+		return convertToBoundSQL(w.String(), w.args, syntaxOf(d), d.Literal)
+	}
 	return ConvertToBoundSQL(w.String(), w.args)
 }
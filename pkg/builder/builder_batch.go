@@ -0,0 +1,113 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Statement is a SQL statement and its args
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// MaxArgs overrides the maximum number of bind parameters per statement of the dialect,
+// e.g. 32766 for SQLite 3.32.0 and later
+func (b *Builder) MaxArgs(n int) *Builder {
+	b.maxArgs = n
+	return b
+}
+
+// ToBatchSQL converts an insert of several rows to as many statements as needed to
+// stay within the bind parameter limit and the rows limit of the dialect. Other builders
+// and inserts within the limits are converted to a single statement.
+func (b *Builder) ToBatchSQL() ([]Statement, error) {
+	maxArgs, maxRows := b.maxArgs, 0
+	if d := GetDialect(b.dialect); d != nil {
+		if maxArgs <= 0 {
+			maxArgs = maxArgsOf(d)
+		}
+		maxRows = maxInsertRowsOf(d)
+	}
+	if b.optype != insertType || len(b.insertRows) <= 1 || (maxArgs <= 0 && maxRows <= 0) {
+		return toStatements(b)
+	}
+	fixed, err := b.fixedArgs()
+	if err != nil {
+		return nil, err
+	}
+	var chunks []*Builder
+	var start, args int
+	for i, row := range b.insertRows {
+		n := rowArgs(row)
+		if maxArgs > 0 && fixed+n > maxArgs {
+			return nil, ErrTooManyArguments
+		}
+		if (maxArgs > 0 && args+n > maxArgs-fixed) || (maxRows > 0 && i-start == maxRows) {
+			chunk := *b
+			chunk.insertRows = b.insertRows[start:i]
+			chunks = append(chunks, &chunk)
+			start, args = i, 0
+		}
+		args += n
+	}
+	chunk := *b
+	chunk.insertRows = b.insertRows[start:]
+	chunks = append(chunks, &chunk)
+	return toStatements(chunks...)
+}
+
+func toStatements(builders ...*Builder) ([]Statement, error) {
+	statements := make([]Statement, 0, len(builders))
+	for _, builder := range builders {
+		sql, args, err := builder.ToSQL()
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, Statement{SQL: sql, Args: args})
+	}
+	return statements, nil
+}
+
+// rowArgs returns the number of args of an inserted row
+func rowArgs(row []interface{}) int {
+	var n int
+	for _, value := range row {
+		switch v := value.(type) {
+		case expr:
+			n += len(v.args)
+		case nil:
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// fixedArgs returns the number of args every chunk of a batch repeats, e.g. the ones of common
+// table expressions, of RETURNING or of updating conflicting rows. They are the args of the insert
+// of the first row but the ones of the row itself.
+func (b *Builder) fixedArgs() (int, error) {
+	first := *b
+	first.insertRows = b.insertRows[:1]
+	_, args, err := first.ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	return len(args) - rowArgs(b.insertRows[0]), nil
+}
@@ -0,0 +1,94 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchRows(n int) []Eq {
+	rows := make([]Eq, 0, n)
+	for i := 0; i < n; i++ {
+		rows = append(rows, Eq{"a": i, "b": fmt.Sprint(i)})
+	}
+	return rows
+}
+
+func TestBuilder_ToBatchSQL(t *testing.T) {
+	// SQL Server inserts at most 1000 rows by a VALUES clause, even within its 2100 parameters
+	statements, err := MsSQL().InsertRows(batchRows(2500)...).Into("table1").ToBatchSQL()
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 3) {
+		assert.Len(t, statements[0].Args, 2000)
+		assert.Len(t, statements[1].Args, 2000)
+		assert.Len(t, statements[2].Args, 1000)
+		assert.EqualValues(t, 999, strings.Count(statements[0].SQL, "),("))
+		assert.True(t, strings.HasSuffix(statements[2].SQL, "(@p999,@p1000)"))
+	}
+	statements, err = Dialect(MSSQL2012).MaxArgs(1500).InsertRows(batchRows(2500)...).Into("table1").ToBatchSQL()
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 4) {
+		assert.Len(t, statements[0].Args, 1500)
+		assert.Len(t, statements[3].Args, 500)
+	}
+
+	statements, err = SQLite().InsertRows(batchRows(1000)...).Into("table1").ToBatchSQL()
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 3) {
+		assert.Len(t, statements[0].Args, 998)
+		assert.Len(t, statements[1].Args, 998)
+		assert.Len(t, statements[2].Args, 4)
+		assert.EqualValues(t, []interface{}{998, "998", 999, "999"}, statements[2].Args)
+	}
+
+	statements, err = SQLite().MaxArgs(32766).InsertRows(batchRows(1000)...).Into("table1").ToBatchSQL()
+	assert.NoError(t, err)
+	assert.Len(t, statements, 1)
+
+	// the updates of conflicting rows are repeated by every chunk
+	statements, err = Postgres().MaxArgs(7).InsertRows(batchRows(5)...).Into("table1").
+		OnConflict("a").DoUpdate(Eq{"b": "z"}).ToBatchSQL()
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 2) {
+		assert.EqualValues(t, "INSERT INTO table1 (a,b) Values ($1,$2),($3,$4),($5,$6) ON CONFLICT (a) DO UPDATE SET b=$7", statements[0].SQL)
+		assert.EqualValues(t, []interface{}{3, "3", 4, "4", "z"}, statements[1].Args)
+	}
+
+	// so are the args of common table expressions
+	statements, err = Postgres().MaxArgs(7).With("old", Select("a").From("table2").Where(Eq{"c": 1})).
+		InsertRows(batchRows(5)...).Into("table1").Returning("a").ToBatchSQL()
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 2) {
+		assert.EqualValues(t, "WITH old AS (SELECT a FROM table2 WHERE c=$1) INSERT INTO table1 (a,b) Values ($2,$3),($4,$5),($6,$7) RETURNING a", statements[0].SQL)
+		assert.EqualValues(t, []interface{}{1, 3, "3", 4, "4"}, statements[1].Args)
+	}
+
+	_, err = Postgres().MaxArgs(1).InsertRows(batchRows(2)...).Into("table1").ToBatchSQL()
+	assert.EqualValues(t, ErrTooManyArguments, err)
+
+	statements, err = MySQL().Select("a").From("table1").Where(Eq{"a": 1}).ToBatchSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []Statement{{SQL: "SELECT a FROM table1 WHERE a=?", Args: []interface{}{1}}}, statements)
+}
@@ -0,0 +1,103 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Clone returns a copy of the builder, which can be extended without changing the builder it
+// was cloned from. Sub-queries, union members and common table expressions are cloned too,
+// conditions, including the orders of Asc and Desc, are shared as they are never changed once
+// created.
+// The slices of the copy are allocated anew, so appending to them never writes to b.
+//
+// Rendering never changes a builder, so a builder can be used as a template rendered by
+// several goroutines at once, while each of them extends a clone of it.
+func (b *Builder) Clone() *Builder {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	c.subQuery = b.subQuery.Clone()
+	c.selects = append(b.selects[:0:0], b.selects...)
+	c.selectExprs = append(b.selectExprs[:0:0], b.selectExprs...)
+	c.joins = append(b.joins[:0:0], b.joins...)
+	c.insertCols = append(b.insertCols[:0:0], b.insertCols...)
+	c.insertVals = append(b.insertVals[:0:0], b.insertVals...)
+	c.returning = append(b.returning[:0:0], b.returning...)
+	c.updates = append(b.updates[:0:0], b.updates...)
+	c.orderBy = append(b.orderBy[:0:0], b.orderBy...)
+	c.groupBy = append(b.groupBy[:0:0], b.groupBy...)
+	c.windows = append(b.windows[:0:0], b.windows...)
+	if b.insertRows != nil {
+		c.insertRows = make([][]interface{}, len(b.insertRows))
+		for i, row := range b.insertRows {
+			c.insertRows[i] = append(row[:0:0], row...)
+		}
+	}
+	if b.setOps != nil {
+		c.setOps = make([]setOp, len(b.setOps))
+		for i, o := range b.setOps {
+			o.builder = o.builder.Clone()
+			c.setOps[i] = o
+		}
+	}
+	if b.ctes != nil {
+		c.ctes = make([]cte, len(b.ctes))
+		for i, e := range b.ctes {
+			e.cols = append(e.cols[:0:0], e.cols...)
+			e.query = e.query.Clone()
+			c.ctes[i] = e
+		}
+	}
+	if b.limitation != nil {
+		limitation := *b.limitation
+		c.limitation = &limitation
+	}
+	if b.upsert != nil {
+		u := *b.upsert
+		u.cols = append(u.cols[:0:0], u.cols...)
+		u.updates = append(u.updates[:0:0], u.updates...)
+		c.upsert = &u
+	}
+	if b.keyset != nil {
+		k := *b.keyset
+		k.keys = append(k.keys[:0:0], k.keys...)
+		k.after = append(k.after[:0:0], k.after...)
+		c.keyset = &k
+	}
+	return &c
+}
+
+// inheritDialect returns the builder rendered as a part of a statement in dialect: b itself if it
+// has a dialect, otherwise a shallow copy of it and of its union members in dialect
+func (b *Builder) inheritDialect(dialect string) *Builder {
+	if b.dialect != "" || dialect == "" {
+		return b
+	}
+	c := *b
+	c.dialect = dialect
+	if b.setOps != nil {
+		c.setOps = make([]setOp, len(b.setOps))
+		for i, o := range b.setOps {
+			o.builder = o.builder.inheritDialect(dialect)
+			c.setOps[i] = o
+		}
+	}
+	return &c
+}
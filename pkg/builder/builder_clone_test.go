@@ -0,0 +1,132 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Clone(t *testing.T) {
+	base := MySQL().Select("id", "name").From("users").Where(Eq{"active": true}).OrderBy("id")
+	clone := base.Clone().Select("id").Where(Gt{"id": 10}).OrderBy("name").Limit(5)
+
+	sql, args, err := base.ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,name FROM users WHERE active=? ORDER BY id", sql)
+	assert.EqualValues(t, []interface{}{true}, args)
+
+	sql, args, err = clone.ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE active=? AND id>? ORDER BY id,name LIMIT 5", sql)
+	assert.EqualValues(t, []interface{}{true, 10}, args)
+
+	insert := Postgres().Insert(Eq{"a": 1}).Into("t").OnConflict("a").DoUpdate(Eq{"b": 2})
+	insertClone := insert.Clone().DoUpdate(Eq{"c": 3})
+	sql, err = insert.ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO t (a) Values (1) ON CONFLICT (a) DO UPDATE SET b=2", sql)
+	sql, err = insertClone.ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO t (a) Values (1) ON CONFLICT (a) DO UPDATE SET c=3", sql)
+
+	// orders are shared, NULLS ordering the clone's ones leaves the original as it is
+	keys := []*Order{Asc("name"), Desc("id")}
+	page := Postgres().Select("id").From("users").OrderBy(keys[0]).Keyset(keys[1:])
+	pageClone := page.Clone().Keyset([]*Order{keys[1].NullsLast()})
+	pageClone.OrderBy(keys[0].NullsFirst())
+	sql, err = page.ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users ORDER BY name ASC,id DESC", sql)
+	sql, err = pageClone.ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users ORDER BY name ASC,id DESC NULLS LAST,name ASC NULLS FIRST", sql)
+
+	var nilBuilder *Builder
+	assert.Nil(t, nilBuilder.Clone())
+}
+
+func TestBuilder_RenderDoesNotMutate(t *testing.T) {
+	// neither the sub-query nor the common table expression have a dialect, they inherit it
+	sub := Select("id").From("orders").Where(Gt{"total": 100})
+	union := Select("id").From("a").Union("ALL", Select("id").From("b"))
+	templates := []*Builder{
+		MsSQL().Select("id").From(sub, "o").OrderBy("id").Limit(5, 10),
+		Oracle().Select("id").From(sub, "o").Limit(5, 10),
+		MsSQL().With("u", union).Select("id").From("u").Limit(5),
+	}
+	for _, template := range templates {
+		before := *template
+		first, err := template.ToBoundSQL()
+		assert.NoError(t, err)
+		second, err := template.ToBoundSQL()
+		assert.NoError(t, err)
+		assert.EqualValues(t, first, second)
+		assert.EqualValues(t, before, *template)
+	}
+	assert.EqualValues(t, "", sub.dialect)
+	assert.EqualValues(t, "", union.dialect)
+
+	// the same templates render in another dialect afterwards
+	sql, err := Postgres().Select("id").From(sub, "o").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM (SELECT id FROM orders WHERE total>100) o", sql)
+	sql, err = Postgres().With("u", union).Select("id").From("u").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH u AS (SELECT id FROM a UNION ALL SELECT id FROM b) SELECT id FROM u", sql)
+}
+
+func TestBuilder_ConcurrentRender(t *testing.T) {
+	sub := Select("id", "user_id").From("orders").Where(Gt{"total": 100})
+	templates := map[string]*Builder{
+		MSSQL: MsSQL().With("big", Select("user_id").From(sub, "o").Union("ALL", Select("user_id").From("users"))).
+			Select("user_id").From("big").OrderBy("user_id").Limit(10, 20),
+		ORACLE: Oracle().Select("user_id").From(sub, "o").OrderBy("user_id").Limit(10, 20),
+		POSTGRES: Postgres().Select("user_id").From(sub, "o").
+			Window("w", NewWindow().OrderBy("user_id")).SelectExpr(WindowFunc("rank").Over(NewWindow("w")).As("r")),
+	}
+	expected := make(map[string]string, len(templates))
+	for name, template := range templates {
+		sql, err := template.ToBoundSQL()
+		assert.NoError(t, err)
+		expected[name] = sql
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for name, template := range templates {
+				sql, err := template.ToBoundSQL()
+				assert.NoError(t, err)
+				assert.EqualValues(t, expected[name], sql)
+
+				sql, err = template.Clone().Limit(i+1, i).ToBoundSQL()
+				assert.NoError(t, err)
+				assert.NotEqual(t, expected[name], sql, name)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -33,8 +33,20 @@ func (b *Builder) deleteWriteTo(w Writer) error {
 	if len(b.from) <= 0 {
 		return ErrNoTableName
 	}
-	if _, err := fmt.Fprintf(w, "DELETE FROM %s WHERE ", b.from); err != nil {
+	if _, err := b.returningStyle(); err != nil {
 		return err
 	}
-	return b.cond.WriteTo(w)
+	if _, err := fmt.Fprintf(w, "DELETE FROM %s", quoteIdent(w, b.from)); err != nil {
+		return err
+	}
+	if err := b.outputWriteTo(w, "DELETED"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, " WHERE "); err != nil {
+		return err
+	}
+	if err := b.cond.WriteTo(w); err != nil {
+		return err
+	}
+	return b.returningWriteTo(w)
 }
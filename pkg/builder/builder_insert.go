@@ -21,7 +21,6 @@ package builder
 // THE SOFTWARE.
 
 import (
-	"bytes"
 	"fmt"
 	"sort"
 )
@@ -31,16 +30,37 @@ func Insert(eq ...interface{}) *Builder {
 	builder := &Builder{cond: NewCond()}
 	return builder.Insert(eq...)
 }
+
+// InsertRows creates an insert Builder of several rows with the same columns
+func InsertRows(rows ...Eq) *Builder {
+	builder := &Builder{cond: NewCond()}
+	return builder.InsertRows(rows...)
+}
 func (b *Builder) insertSelectWriteTo(w Writer) error {
-	if _, err := fmt.Fprintf(w, "INSERT INTO %s ", b.into); err != nil {
+	if _, err := fmt.Fprintf(w, "INSERT INTO %s", quoteIdent(w, b.into)); err != nil {
 		return err
 	}
 	if len(b.insertCols) > 0 {
-		fmt.Fprintf(w, "(")
-		for _, col := range b.insertCols {
-			fmt.Fprintf(w, col)
+		fmt.Fprintf(w, " (")
+		for i, col := range b.insertCols {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprint(w, quoteIdent(w, col))
+		}
+		fmt.Fprintf(w, ")")
+	}
+	if err := b.outputWriteTo(w, "INSERTED"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, " "); err != nil {
+		return err
+	}
+	if !b.supports(FeatureWithInsert) {
+		// the common table expressions belong to the selected rows
+		if err := b.withWriteTo(w); err != nil {
+			return err
 		}
-		fmt.Fprintf(w, ") ")
 	}
 	return b.selectWriteTo(w)
 }
@@ -51,49 +71,174 @@ func (b *Builder) insertWriteTo(w Writer) error {
 	if len(b.insertCols) <= 0 && b.from == "" {
 		return ErrNoColumnToInsert
 	}
+	if b.from == "" {
+		for _, row := range b.insertRowValues() {
+			if len(row) != len(b.insertCols) {
+				return ErrInconsistentRows
+			}
+		}
+	}
+	style, err := b.returningStyle()
+	if err != nil {
+		return err
+	}
+	// RETURNING ... INTO returns the values of a single row inserted by VALUES
+	if style == FeatureReturningInto && (b.from != "" || b.upsert != nil || len(b.insertRowValues()) > 1) {
+		return ErrNotSupportReturning
+	}
+	if len(b.ctes) > 0 && b.supports(FeatureWithInsert) {
+		if err := b.withWriteTo(w); err != nil {
+			return err
+		}
+	} else if len(b.ctes) > 0 && b.from == "" {
+		return ErrNotSupportCTE
+	}
 	if b.into != "" && b.from != "" {
-		return b.insertSelectWriteTo(w)
+		if b.upsert != nil {
+			return ErrUnsupportedUpsert
+		}
+		if err := b.insertSelectWriteTo(w); err != nil {
+			return err
+		}
+	} else if b.upsert != nil {
+		if err := b.upsertWriteTo(w); err != nil {
+			return err
+		}
+	} else if err := b.insertValuesWriteTo(w); err != nil {
+		return err
 	}
-	if _, err := fmt.Fprintf(w, "INSERT INTO %s (", b.into); err != nil {
+	return b.returningWriteTo(w)
+}
+func (b *Builder) insertValuesWriteTo(w Writer) error {
+	rows := b.insertRowValues()
+	if len(rows) > 1 {
+		if d := GetDialect(b.dialect); d != nil && !d.Supports(FeatureMultiRowInsert) {
+			return b.insertAllWriteTo(w, d, rows)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "INSERT INTO %s (", quoteIdent(w, b.into)); err != nil {
 		return err
 	}
-	var args = make([]interface{}, 0)
-	var bs []byte
-	var valBuffer = bytes.NewBuffer(bs)
-	for i, col := range b.insertCols {
-		value := b.insertVals[i]
-		fmt.Fprint(w, col)
-		if e, ok := value.(expr); ok {
-			fmt.Fprintf(valBuffer, "(%s)", e.sql)
-			args = append(args, e.args...)
-		} else if value == nil {
-			fmt.Fprintf(valBuffer, `null`)
-		} else {
-			fmt.Fprint(valBuffer, "?")
-			args = append(args, value)
-		}
-		if i != len(b.insertCols)-1 {
+	if err := b.insertColsWriteTo(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, ")"); err != nil {
+		return err
+	}
+	if err := b.outputWriteTo(w, "INSERTED"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, " Values "); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if i > 0 {
 			if _, err := fmt.Fprint(w, ","); err != nil {
 				return err
 			}
-			if _, err := fmt.Fprint(valBuffer, ","); err != nil {
-				return err
-			}
+		}
+		if err := writeInsertRow(w, row); err != nil {
+			return err
 		}
 	}
-	if _, err := fmt.Fprint(w, ") Values ("); err != nil {
+	return nil
+}
+
+// insertAllWriteTo inserts several rows with INSERT ALL for dialects without multi-row VALUES
+func (b *Builder) insertAllWriteTo(w Writer, d SQLDialect, rows [][]interface{}) error {
+	if len(b.returning) > 0 {
+		return ErrNotSupportReturning
+	}
+	if _, err := fmt.Fprint(w, "INSERT ALL"); err != nil {
 		return err
 	}
-	if _, err := w.Write(valBuffer.Bytes()); err != nil {
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, " INTO %s (", quoteIdent(w, b.into)); err != nil {
+			return err
+		}
+		if err := b.insertColsWriteTo(w); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(w, ") VALUES "); err != nil {
+			return err
+		}
+		if err := writeInsertRow(w, row); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprint(w, " SELECT 1"); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprint(w, ")"); err != nil {
+	if d.Supports(FeatureDualTable) {
+		if _, err := fmt.Fprint(w, " FROM DUAL"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Builder) insertColsWriteTo(w Writer) error {
+	for i, col := range b.insertCols {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, quoteIdent(w, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInsertRow writes the values of a row in parentheses
+func writeInsertRow(w Writer, row []interface{}) error {
+	if _, err := fmt.Fprint(w, "("); err != nil {
 		return err
 	}
-	w.Append(args...)
+	for i, value := range row {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeInsertValue(w, value); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, ")")
+	return err
+}
+
+// writeInsertValue writes an inserted value, expressions in parentheses
+func writeInsertValue(w Writer, value interface{}) error {
+	switch v := value.(type) {
+	case expr:
+		if _, err := fmt.Fprintf(w, "(%s)", v.sql); err != nil {
+			return err
+		}
+		w.Append(v.args...)
+	case nil:
+		if _, err := fmt.Fprint(w, "null"); err != nil {
+			return err
+		}
+	default:
+		if _, err := fmt.Fprint(w, "?"); err != nil {
+			return err
+		}
+		w.Append(v)
+	}
 	return nil
 }
 
+// insertRowValues returns the values of the inserted rows in the order of the columns
+func (b *Builder) insertRowValues() [][]interface{} {
+	if b.insertRows != nil {
+		return b.insertRows
+	}
+	return [][]interface{}{b.insertVals}
+}
+
 type insertColsSorter struct {
 	cols []string
 	vals []interface{}
@@ -110,11 +255,17 @@ func (s insertColsSorter) Less(i, j int) bool {
 	return s.cols[i] < s.cols[j]
 }
 
-// Insert sets insert SQL
+// Insert sets insert SQL of an Eq, a struct mapped by StructEq or columns to insert the rows of a select into
 func (b *Builder) Insert(eq ...interface{}) *Builder {
 	if len(eq) > 0 {
 		var paramType = -1
 		for _, e := range eq {
+			if _, ok := e.(string); !ok {
+				// structs are inserted by their mapped fields, see StructEq
+				if row, err := StructEq(e); err == nil {
+					e = row
+				}
+			}
 			switch t := e.(type) {
 			case Eq:
 				if paramType == -1 {
@@ -147,3 +298,27 @@ func (b *Builder) Insert(eq ...interface{}) *Builder {
 	b.optype = insertType
 	return b
 }
+
+// InsertRows sets insert SQL of several rows with the same columns
+func (b *Builder) InsertRows(rows ...Eq) *Builder {
+	b.insertCols, b.insertVals, b.insertRows = nil, nil, nil
+	if len(rows) > 0 {
+		b.insertCols = rows[0].sortedKeys()
+		b.insertRows = make([][]interface{}, 0, len(rows))
+		for _, row := range rows {
+			vals := make([]interface{}, 0, len(b.insertCols))
+			for _, col := range b.insertCols {
+				v, ok := row[col]
+				if !ok || len(row) != len(b.insertCols) {
+					// rows with other columns are reported by insertWriteTo
+					vals = nil
+					break
+				}
+				vals = append(vals, v)
+			}
+			b.insertRows = append(b.insertRows, vals)
+		}
+	}
+	b.optype = insertType
+	return b
+}
@@ -60,3 +60,35 @@ func TestBuidlerInsert_Select(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, "INSERT INTO table1 (a, b) SELECT b, c FROM table2", sql)
 }
+func TestBuilderInsertRows(t *testing.T) {
+	sql, args, err := Postgres().InsertRows(Eq{"a": 1, "b": "x"}, Eq{"b": "y", "a": 2}, Eq{"a": Expr("?+1", 3), "b": nil}).
+		Into("table1").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (a,b) Values ($1,$2),($3,$4),(($5+1),null)", sql)
+	assert.EqualValues(t, []interface{}{1, "x", 2, "y", 3}, args)
+
+	sql, err = Oracle().InsertRows(Eq{"a": 1, "b": "x"}, Eq{"a": 2, "b": "y"}).Into("table1").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT ALL INTO table1 (a,b) VALUES (1,'x') INTO table1 (a,b) VALUES (2,'y') SELECT 1 FROM DUAL", sql)
+
+	sql, err = Oracle().InsertRows(Eq{"a": 1, "b": "x"}).Into("table1").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (a,b) Values (1,'x')", sql)
+
+	sql, err = Postgres().InsertRows(Eq{"id": 1, "b": "x"}, Eq{"id": 2, "b": "y"}).Into("table1").
+		OnConflict("id").DoUpdate().ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (b,id) Values ('x',1),('y',2) ON CONFLICT (id) DO UPDATE SET b=excluded.b", sql)
+
+	sql, err = MsSQL().InsertRows(Eq{"id": 1, "b": "x"}, Eq{"id": 2, "b": "y"}).Into("table1").
+		OnConflict("id").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "MERGE INTO table1 WITH (HOLDLOCK) target USING (SELECT 'x' b,1 id UNION ALL SELECT 'y' b,2 id) excluded ON (target.id=excluded.id) WHEN NOT MATCHED THEN INSERT (b,id) VALUES (excluded.b,excluded.id);", sql)
+
+	_, err = MySQL().InsertRows(Eq{"a": 1, "b": "x"}, Eq{"a": 2}).Into("table1").ToBoundSQL()
+	assert.EqualValues(t, ErrInconsistentRows, err)
+	_, err = MySQL().InsertRows(Eq{"a": 1}, Eq{"c": 2}).Into("table1").ToBoundSQL()
+	assert.EqualValues(t, ErrInconsistentRows, err)
+	_, err = MySQL().InsertRows().Into("table1").ToBoundSQL()
+	assert.EqualValues(t, ErrNoColumnToInsert, err)
+}
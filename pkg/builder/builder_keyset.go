@@ -0,0 +1,280 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type keyset struct {
+	keys  []*Order
+	after []interface{}
+	// orderAt is the position of the keys in the ORDER BY terms of the builder
+	orderAt int
+}
+
+// Keyset pages a select by its keys, which must identify a row and must not be NULL. The rows are
+// ordered by the keys and, if after holds the key values of the last row of the previous page, start
+// after that row. Limit sets the size of the page. Calling Keyset again replaces the keys and the
+// values of the previous call.
+func (b *Builder) Keyset(keys []*Order, after ...interface{}) *Builder {
+	if prev := b.keyset; prev != nil {
+		end := prev.orderAt + len(prev.keys)
+		b.orderBy = append(b.orderBy[:prev.orderAt:prev.orderAt], b.orderBy[end:]...)
+	}
+	b.keyset = &keyset{keys: keys, after: after, orderAt: len(b.orderBy)}
+	for _, k := range keys {
+		b.orderBy = append(b.orderBy, k)
+	}
+	return b
+}
+
+// keysetCond returns the condition selecting the rows after the last one of the previous page
+func (b *Builder) keysetCond() (Cond, error) {
+	ks := b.keyset
+	if len(ks.after) == 0 {
+		return NewCond(), nil
+	}
+	if len(ks.after) != len(ks.keys) {
+		return nil, ErrInvalidCursor
+	}
+	sameDir := true
+	for _, k := range ks.keys[1:] {
+		sameDir = sameDir && k.desc == ks.keys[0].desc
+	}
+	if len(ks.keys) > 1 && sameDir && b.supports(FeatureRowValues) {
+		return rowCompare{keys: ks.keys, values: ks.after}, nil
+	}
+	// (a>?) OR (a=? AND b>?) OR (a=? AND b=? AND c>?) ...
+	or := make([]Cond, 0, len(ks.keys))
+	for i, k := range ks.keys {
+		and := make([]Cond, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, Eq{ks.keys[j].col: ks.after[j]})
+		}
+		if k.desc {
+			and = append(and, Lt{k.col: ks.after[i]})
+		} else {
+			and = append(and, Gt{k.col: ks.after[i]})
+		}
+		or = append(or, And(and...))
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return Or(or...), nil
+}
+
+// rowCompare compares the row value of keys to values, e.g. (a,b)>(?,?)
+type rowCompare struct {
+	keys   []*Order
+	values []interface{}
+}
+
+var _ Cond = rowCompare{}
+
+func (r rowCompare) WriteTo(w Writer) error {
+	cols := make([]string, len(r.keys))
+	for i, k := range r.keys {
+		cols[i] = quoteIdent(w, k.col)
+	}
+	op := ">"
+	if r.keys[0].desc {
+		op = "<"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(r.values)), ",")
+	if _, err := fmt.Fprintf(w, "(%s)%s(%s)", strings.Join(cols, ","), op, placeholders); err != nil {
+		return err
+	}
+	w.Append(r.values...)
+	return nil
+}
+
+func (r rowCompare) And(conds ...Cond) Cond {
+	return And(r, And(conds...))
+}
+
+func (r rowCompare) Or(conds ...Cond) Cond {
+	return Or(r, Or(conds...))
+}
+
+func (r rowCompare) IsValid() bool {
+	return len(r.keys) > 0
+}
+
+// cursorValue is a value of a cursor along with its type, which JSON would lose
+type cursorValue struct {
+	T string `json:"t"`
+	V string `json:"v,omitempty"`
+}
+
+// EncodeCursor encodes the key values of the last row of a page into an opaque token,
+// which DecodeCursor turns back into the values to pass to Keyset for the next page.
+// Values can be nil, booleans, numbers, strings, byte slices and time.Time, types based on
+// them, pointers to them and driver.Valuer implementations returning them.
+func EncodeCursor(values ...interface{}) (string, error) {
+	res := make([]cursorValue, len(values))
+	for i, v := range values {
+		cv, err := encodeCursorValue(v)
+		if err != nil {
+			return "", err
+		}
+		res[i] = cv
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func encodeCursorValue(v interface{}) (cursorValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return cursorValue{T: "n"}, nil
+		}
+		if rv.Type().Implements(valuerType) {
+			break
+		}
+		rv = rv.Elem()
+	}
+	if rv.IsValid() && !rv.Type().Implements(valuerType) && reflect.PtrTo(rv.Type()).Implements(valuerType) {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv = ptr
+	}
+	if rv.IsValid() && rv.Type().Implements(valuerType) {
+		value, err := rv.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return cursorValue{}, err
+		}
+		rv = reflect.ValueOf(value)
+	}
+	if !rv.IsValid() {
+		return cursorValue{T: "n"}, nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return cursorValue{T: "b", V: strconv.FormatBool(rv.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{T: "i", V: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{T: "u", V: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{T: "f", V: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.String:
+		return cursorValue{T: "s", V: rv.String()}, nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return cursorValue{T: "x", V: base64.StdEncoding.EncodeToString(rv.Bytes())}, nil
+		}
+	case reflect.Struct:
+		if rv.Type().ConvertibleTo(timeType) {
+			t := rv.Convert(timeType).Interface().(time.Time)
+			return cursorValue{T: "t", V: t.Format(time.RFC3339Nano)}, nil
+		}
+	}
+	return cursorValue{}, ErrInvalidCursor
+}
+
+// DecodeCursor decodes a token created by EncodeCursor. An empty token, i.e. the one of the first
+// page, has no values.
+func DecodeCursor(token string) ([]interface{}, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cvs []cursorValue
+	if err := json.Unmarshal(data, &cvs); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	res := make([]interface{}, len(cvs))
+	for i, cv := range cvs {
+		var (
+			v   interface{}
+			err error
+		)
+		switch cv.T {
+		case "n":
+		case "b":
+			v, err = strconv.ParseBool(cv.V)
+		case "i":
+			v, err = strconv.ParseInt(cv.V, 10, 64)
+		case "u":
+			v, err = strconv.ParseUint(cv.V, 10, 64)
+		case "f":
+			v, err = strconv.ParseFloat(cv.V, 64)
+		case "s":
+			v = cv.V
+		case "x":
+			v, err = base64.StdEncoding.DecodeString(cv.V)
+		case "t":
+			v, err = time.Parse(time.RFC3339Nano, cv.V)
+		default:
+			return nil, ErrInvalidCursor
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// StructCursor encodes the values of the fields of the struct v which keys are mapped to, see
+// EncodeCursor. v is usually the last row of a page scanned by an Executor.
+func StructCursor(v interface{}, keys ...*Order) (string, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return "", err
+	}
+	fields := structFields(rv.Type())
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		col := k.col
+		if dot := strings.LastIndex(col, "."); dot >= 0 {
+			col = col[dot+1:]
+		}
+		f, ok := fieldOfColumn(fields, col)
+		if !ok {
+			return "", ErrInvalidCursor
+		}
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			return "", ErrInvalidCursor
+		}
+		values[i] = fieldValue(fv)
+	}
+	return EncodeCursor(values...)
+}
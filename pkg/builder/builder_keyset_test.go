@@ -0,0 +1,130 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Keyset(t *testing.T) {
+	keys := []*Order{Desc("created"), Desc("id")}
+
+	sql, args, err := Postgres().Select("id").From("engine").Where(Eq{"phase": 1}).
+		Keyset(keys).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE phase=$1 ORDER BY created DESC,id DESC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{1}, args)
+
+	sql, args, err = Postgres().Select("id").From("engine").Where(Eq{"phase": 1}).
+		Keyset(keys, 100, 7).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE phase=$1 AND (created,id)<($2,$3) ORDER BY created DESC,id DESC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{1, 100, 7}, args)
+
+	sql, args, err = MsSQL().Select("id").From("engine").Keyset(keys, 100, 7).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 10 id FROM engine WHERE (created<@p1) OR (created=@p2 AND id<@p3) ORDER BY created DESC,id DESC", sql)
+	assert.Len(t, args, 3)
+
+	sql, args, err = Postgres().QuoteIdents().Select("id").From("engine e").Where(Eq{"phase": 1}).
+		Keyset([]*Order{Asc("e.name"), Desc("e.id")}, "a", 7).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT "id" FROM "engine" "e" WHERE "phase"=$1 AND (("e"."name">$2) OR ("e"."name"=$3 AND "e"."id"<$4)) ORDER BY "e"."name" ASC,"e"."id" DESC LIMIT 10`, sql)
+	assert.EqualValues(t, []interface{}{1, "a", "a", 7}, args)
+
+	sql, args, err = MySQL().Select("id").From("engine").Keyset([]*Order{Asc("id")}, 7).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE id>? ORDER BY id ASC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{7}, args)
+
+	_, _, err = Postgres().Select("id").From("engine").Keyset(keys, 100).ToSQL()
+	assert.EqualValues(t, ErrInvalidCursor, err)
+
+	// a later Keyset replaces the keys and values of the previous one, also on a clone
+	page := Postgres().Select("id").From("engine").OrderBy("phase").Keyset(keys).OrderBy("name").Limit(10)
+	sql, args, err = page.Keyset([]*Order{Asc("id")}, 7).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE id>$1 ORDER BY phase,name,id ASC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{7}, args)
+
+	next := page.Clone().Keyset([]*Order{Asc("id")}, 8)
+	sql, args, err = next.ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE id>$1 ORDER BY phase,name,id ASC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{8}, args)
+	sql, args, err = page.ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM engine WHERE id>$1 ORDER BY phase,name,id ASC LIMIT 10", sql)
+	assert.EqualValues(t, []interface{}{7}, args)
+}
+
+func TestCursor(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	token, err := EncodeCursor(created, int32(7), "a/b", nil, true, 1.5, uint(3), []byte{1, 2})
+	assert.NoError(t, err)
+	assert.NotContains(t, token, "/")
+
+	values, err := DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{created, int64(7), "a/b", nil, true, 1.5, uint64(3), []byte{1, 2}}, values)
+
+	values, err = DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+
+	_, err = DecodeCursor("not a cursor")
+	assert.EqualValues(t, ErrInvalidCursor, err)
+	_, err = EncodeCursor(struct{}{})
+	assert.EqualValues(t, ErrInvalidCursor, err)
+
+	token, err = StructCursor(&account{ID: 7, UserName: "cat"}, Asc("a.user_name"), Desc("id"))
+	assert.NoError(t, err)
+	values, err = DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{"cat", int64(7)}, values)
+
+	_, err = StructCursor(account{}, Asc("unknown"))
+	assert.EqualValues(t, ErrInvalidCursor, err)
+
+	// named types, pointers and valuers are encoded by the values they stand for
+	type userID int64
+	type stamp time.Time
+	id := userID(7)
+	token, err = EncodeCursor(id, &id, (*userID)(nil), stamp(created), sql2.NullInt64{Int64: 3, Valid: true}, sql2.NullString{})
+	assert.NoError(t, err)
+	values, err = DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{int64(7), int64(7), nil, created, int64(3), nil}, values)
+
+	type member struct {
+		ID    userID          `db:"id"`
+		Email sql2.NullString `db:"email"`
+	}
+	token, err = StructCursor(member{ID: 7, Email: sql2.NullString{String: "cat@example.com", Valid: true}}, Asc("email"), Asc("id"))
+	assert.NoError(t, err)
+	values, err = DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{"cat@example.com", int64(7)}, values)
+}
@@ -22,85 +22,228 @@ package builder
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
 func (b *Builder) limitWriteTo(w Writer) error {
-	if strings.TrimSpace(b.dialect) == "" {
-		return ErrDialectNotSetUp
+	d, err := b.requireDialect()
+	if err != nil {
+		return err
 	}
 	if b.limitation != nil {
 		limit := b.limitation
 		if limit.offset < 0 || limit.limitN <= 0 {
 			return ErrInvalidLimitation
 		}
-		// erase limit condition
-		b.limitation = nil
-		defer func() {
-			b.limitation = limit
-		}()
-		ow := w.(*BytesWriter)
-		switch strings.ToLower(strings.TrimSpace(b.dialect)) {
-		case ORACLE:
-			if len(b.selects) == 0 {
-				b.selects = append(b.selects, "*")
+		switch d.Pagination() {
+		case PaginationRowNum, PaginationTopRowNumber:
+			// wrap a copy without limit condition and common table expressions, they precede the wrapping query
+			query := *b
+			query.limitation, query.ctes = nil, nil
+			if d.Pagination() == PaginationRowNum {
+				return query.rowNumWriteTo(w, limit)
 			}
-			var final *Builder
-			selects := b.selects
-			b.selects = append(selects, "ROWNUM RN")
-			var wb *Builder
-			if b.optype == setOpType {
-				wb = Dialect(b.dialect).Select("at.*", "ROWNUM RN").
-					From(b, "at")
-			} else {
-				wb = b
-			}
-			if limit.offset == 0 {
-				final = Dialect(b.dialect).Select(selects...).From(wb, "at").
-					Where(Lte{"at.RN": limit.limitN})
-			} else {
-				sub := Dialect(b.dialect).Select("*").
-					From(b, "at").Where(Lte{"at.RN": limit.offset + limit.limitN})
-				final = Dialect(b.dialect).Select(selects...).From(sub, "att").
-					Where(Gt{"att.RN": limit.offset})
-			}
-			return final.WriteTo(ow)
-		case SQLITE, MYSQL, POSTGRES:
+			return query.topRowNumberWriteTo(w, limit)
+		case PaginationLimitOffset:
 			// if type UNION, we need to write previous content back to current writer
 			if b.optype == setOpType {
-				if err := b.WriteTo(ow); err != nil {
+				if err := b.WriteTo(w); err != nil {
 					return err
 				}
 			}
 			if limit.offset == 0 {
-				fmt.Fprint(ow, " LIMIT ", limit.limitN)
+				fmt.Fprint(w, " LIMIT ", limit.limitN)
 			} else {
-				fmt.Fprintf(ow, " LIMIT %v OFFSET %v", limit.limitN, limit.offset)
+				fmt.Fprintf(w, " LIMIT %v OFFSET %v", limit.limitN, limit.offset)
 			}
-		case MSSQL:
-			if len(b.selects) == 0 {
-				b.selects = append(b.selects, "*")
-			}
-			var final *Builder
-			selects := b.selects
-			b.selects = append(append([]string{fmt.Sprintf("TOP %d %v", limit.limitN+limit.offset, b.selects[0])},
-				b.selects[1:]...), "ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN")
-			var wb *Builder
+		case PaginationOffsetFetch:
 			if b.optype == setOpType {
-				wb = Dialect(b.dialect).Select("*", "ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN").
-					From(b, "at")
-			} else {
-				wb = b
+				if err := b.WriteTo(w); err != nil {
+					return err
+				}
 			}
-			if limit.offset == 0 {
-				final = Dialect(b.dialect).Select(selects...).From(wb, "at")
-			} else {
-				final = Dialect(b.dialect).Select(selects...).From(wb, "at").Where(Gt{"at.RN": limit.offset})
+			if len(b.orderBy) == 0 && d.Supports(FeatureOrderedOffset) {
+				fmt.Fprint(w, " ORDER BY (SELECT NULL)")
 			}
-			return final.WriteTo(ow)
+			fmt.Fprintf(w, " OFFSET %v ROWS FETCH NEXT %v ROWS ONLY", limit.offset, limit.limitN)
 		default:
-			return ErrNotSupportType
+			return ErrNotSupportDialectType
 		}
 	}
 	return nil
 }
+
+// rowNumWriteTo pages the query by the ROWNUM of its ordered rows:
+//
+//	SELECT * FROM (query) at WHERE ROWNUM<=limit
+//	SELECT cols FROM (SELECT at.*,ROWNUM RN FROM (query) at WHERE ROWNUM<=offset+limit) att WHERE att.RN>offset
+func (b *Builder) rowNumWriteTo(w Writer, limit *limit) error {
+	if limit.offset == 0 {
+		return Dialect(b.dialect).Select("*").From(b, "at").
+			Where(Expr("ROWNUM<=?", limit.limitN)).WriteTo(w)
+	}
+	names, err := b.selectNames()
+	if err != nil {
+		return err
+	}
+	numbered := Dialect(b.dialect).Select("at.*").SelectExpr(Expr("ROWNUM RN")).From(b, "at").
+		Where(Expr("ROWNUM<=?", limit.offset+limit.limitN))
+	return Dialect(b.dialect).Select(names...).From(numbered, "att").
+		Where(Gt{"att.RN": limit.offset}).WriteTo(w)
+}
+
+// topRowNumberWriteTo pages the query by SELECT TOP or, with an offset, by the ROW_NUMBER() of its rows
+// in the order of the query:
+//
+//	SELECT TOP limit cols FROM ... ORDER BY order
+//	SELECT TOP limit cols FROM (SELECT cols,ROW_NUMBER() OVER (ORDER BY order) AS RN FROM ...) at WHERE at.RN>offset ORDER BY at.RN
+//
+// The rows of a DISTINCT query or a query ordered by aliases of its columns are numbered outside of it,
+// where the aliases are known and the numbers do not make every row distinct:
+//
+//	SELECT TOP limit cols FROM (SELECT at.*,ROW_NUMBER() OVER (ORDER BY order) AS RN FROM (SELECT ...) at) att WHERE att.RN>offset ORDER BY att.RN
+//
+// TOP allows the query to be used as derived table or union member despite its ORDER BY.
+func (b *Builder) topRowNumberWriteTo(w Writer, limit *limit) error {
+	// b is a copy made by limitWriteTo
+	query := b
+	if b.optype == setOpType {
+		query = Dialect(b.dialect).Select("*").From(b, "at")
+	}
+	if limit.offset == 0 {
+		query.limitTop = limit.limitN
+		return query.selectWriteTo(w)
+	}
+	names, err := b.selectNames()
+	if err != nil {
+		return err
+	}
+	var final *Builder
+	if b.optype != setOpType && (b.isDistinct() || b.ordersByAlias()) {
+		numbered := Dialect(b.dialect).Select("at.*").From(query, "at")
+		numbered.orderBy, query.orderBy = query.orderBy, nil
+		numbered.limitRowNumber = true
+		final = Dialect(b.dialect).Select(names...).From(numbered, "att").
+			Where(Gt{"att.RN": limit.offset}).OrderBy(Asc("att.RN"))
+	} else {
+		query.limitRowNumber = true
+		final = Dialect(b.dialect).Select(names...).From(query, "at").
+			Where(Gt{"at.RN": limit.offset}).OrderBy(Asc("at.RN"))
+	}
+	final.limitTop = limit.limitN
+	return final.WriteTo(w)
+}
+
+// rowNumberWriteTo writes the ROW_NUMBER() column numbering the rows in the order of the query
+func (b *Builder) rowNumberWriteTo(w Writer) error {
+	if _, err := fmt.Fprint(w, ",ROW_NUMBER() OVER ("); err != nil {
+		return err
+	}
+	if len(b.orderBy) == 0 {
+		if _, err := fmt.Fprint(w, "ORDER BY (SELECT 1)"); err != nil {
+			return err
+		}
+	} else {
+		ow := w
+		if d := GetDialect(b.dialect); d != nil {
+			// orders by Asc and Desc emulate NULLS FIRST and NULLS LAST if the dialect has none
+			ow = withDialect(w, d)
+		}
+		if err := writeList(ow, "ORDER BY ", b.orderBy); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, ") AS RN")
+	return err
+}
+
+// distinctPrefix matches the DISTINCT keyword leading the columns of a select
+var distinctPrefix = regexp.MustCompile(`(?i)^\s*DISTINCT\s+`)
+
+// isDistinct reports whether the select returns distinct rows only
+func (b *Builder) isDistinct() bool {
+	return len(b.selects) > 0 && distinctPrefix.MatchString(b.selects[0])
+}
+
+// selectAliases returns the aliases of the selected columns and expressions
+func (b *Builder) selectAliases() []string {
+	var aliases []string
+	for _, s := range b.selects {
+		ref := distinctPrefix.ReplaceAllString(strings.TrimSpace(s), "")
+		if m := identAlias.FindStringSubmatch(ref); m != nil {
+			aliases = append(aliases, m[2])
+		}
+	}
+	for _, e := range b.selectExprs {
+		if we, ok := e.(*WindowExpr); ok && we.alias != "" {
+			aliases = append(aliases, we.alias)
+		}
+	}
+	return aliases
+}
+
+// ordersByAlias reports whether a term of the ORDER BY clause is the alias of a selected column
+func (b *Builder) ordersByAlias() bool {
+	aliases := b.selectAliases()
+	isAlias := func(term string) bool {
+		m := orderTerm.FindStringSubmatch(strings.TrimSpace(term))
+		if m == nil {
+			return false
+		}
+		for _, alias := range aliases {
+			if strings.EqualFold(m[1], alias) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, o := range b.orderBy {
+		switch t := o.(type) {
+		case *Order:
+			if isAlias(t.col) {
+				return true
+			}
+		case expr:
+			for _, term := range strings.Split(t.sql, ",") {
+				if isAlias(term) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// selectNames returns the names a query wrapping the select refers to its columns by, which are their
+// aliases or column names, or * if all columns are selected. Expressions without an alias cannot be
+// referred to, which is an error - the wrapping query would return the RN column otherwise.
+func (b *Builder) selectNames() ([]string, error) {
+	names := make([]string, 0, len(b.selects)+len(b.selectExprs))
+	for _, s := range b.selects {
+		ref := distinctPrefix.ReplaceAllString(strings.TrimSpace(s), "")
+		if strings.HasSuffix(ref, "*") && identPath.MatchString(ref) || ref == "*" {
+			return []string{"*"}, nil
+		}
+		if m := identAlias.FindStringSubmatch(ref); m != nil && !strings.EqualFold(m[1], "DISTINCT") {
+			names = append(names, m[2])
+			continue
+		}
+		if !identPath.MatchString(ref) {
+			return nil, ErrUnnamedPagedColumn
+		}
+		segments := identSplit.FindAllString(ref, -1)
+		names = append(names, segments[len(segments)-1])
+	}
+	for _, e := range b.selectExprs {
+		we, ok := e.(*WindowExpr)
+		if !ok || we.alias == "" {
+			return nil, ErrUnnamedPagedColumn
+		}
+		names = append(names, we.alias)
+	}
+	if len(names) == 0 {
+		return []string{"*"}, nil
+	}
+	return names, nil
+}
@@ -36,13 +36,13 @@ func TestBuilder_Limit4Mssql(t *testing.T) {
 	sql, err := Dialect(MSSQL).Select("a", "b", "c").From("table1").
 		OrderBy("a ASC").Limit(5).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT TOP 5 a,b,c,ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN FROM table1 ORDER BY a ASC) at", sql)
+	assert.EqualValues(t, "SELECT TOP 5 a,b,c FROM table1 ORDER BY a ASC", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// simple with where -- MsSQL style
 	sql, err = Dialect(MSSQL).Select("a", "b", "c").From("table1").
 		Where(Neq{"a": "3"}).OrderBy("a ASC").Limit(5, 10).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT TOP 15 a,b,c,ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN FROM table1 WHERE a<>'3' ORDER BY a ASC) at WHERE at.RN>10", sql)
+	assert.EqualValues(t, "SELECT TOP 5 a,b,c FROM (SELECT a,b,c,ROW_NUMBER() OVER (ORDER BY a ASC) AS RN FROM table1 WHERE a<>'3') at WHERE at.RN>10 ORDER BY at.RN ASC", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// union with limit -- MsSQL style
 	sql, err = Dialect(MSSQL).Select("a", "b", "c").From(
@@ -51,7 +51,7 @@ func TestBuilder_Limit4Mssql(t *testing.T) {
 			Select("a", "b", "c").From("table1").Where(Neq{"b": "2"}).OrderBy("a DESC").Limit(10)), "at").
 		OrderBy("b DESC").Limit(7, 9).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT TOP 16 a,b,c,ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN FROM ((SELECT a,b,c FROM (SELECT TOP 11 a,b,c,ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN FROM table1 WHERE a<>'1' ORDER BY a ASC) at WHERE at.RN>6) UNION ALL (SELECT a,b,c FROM (SELECT TOP 10 a,b,c,ROW_NUMBER() OVER (ORDER BY (SELECT 1)) AS RN FROM table1 WHERE b<>'2' ORDER BY a DESC) at)) at ORDER BY b DESC) at WHERE at.RN>9", sql)
+	assert.EqualValues(t, "SELECT TOP 7 a,b,c FROM (SELECT a,b,c,ROW_NUMBER() OVER (ORDER BY b DESC) AS RN FROM ((SELECT TOP 5 a,b,c FROM (SELECT a,b,c,ROW_NUMBER() OVER (ORDER BY a ASC) AS RN FROM table1 WHERE a<>'1') at WHERE at.RN>6 ORDER BY at.RN ASC) UNION ALL (SELECT TOP 10 a,b,c FROM table1 WHERE b<>'2' ORDER BY a DESC)) at) at WHERE at.RN>9 ORDER BY at.RN ASC", sql)
 	assert.NoError(t, f.executableCheck(sql))
 }
 func TestBuilder_Limit4MysqlLike(t *testing.T) {
@@ -98,25 +98,25 @@ func TestBuilder_Limit4Oracle(t *testing.T) {
 	sql, err := Dialect(ORACLE).Select("a", "b", "c").From("table1").OrderBy("a ASC").
 		Limit(5, 10).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT * FROM (SELECT a,b,c,ROWNUM RN FROM table1 ORDER BY a ASC) at WHERE at.RN<=15) att WHERE att.RN>10", sql)
+	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT at.*,ROWNUM RN FROM (SELECT a,b,c FROM table1 ORDER BY a ASC) at WHERE ROWNUM<=15) att WHERE att.RN>10", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// simple with join -- OracleSQL style
 	sql, err = Dialect(ORACLE).Select("a", "b", "c", "d").From("table1 t1").
 		InnerJoin("table2 t2", "t1.id = t2.ref_id").OrderBy("a ASC").Limit(5, 10).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c,d FROM (SELECT * FROM (SELECT a,b,c,d,ROWNUM RN FROM table1 t1 INNER JOIN table2 t2 ON t1.id = t2.ref_id ORDER BY a ASC) at WHERE at.RN<=15) att WHERE att.RN>10", sql)
+	assert.EqualValues(t, "SELECT a,b,c,d FROM (SELECT at.*,ROWNUM RN FROM (SELECT a,b,c,d FROM table1 t1 INNER JOIN table2 t2 ON t1.id = t2.ref_id ORDER BY a ASC) at WHERE ROWNUM<=15) att WHERE att.RN>10", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// simple -- OracleSQL style
 	sql, err = Dialect(ORACLE).Select("a", "b", "c").From("table1").
 		OrderBy("a ASC").Limit(5).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT a,b,c,ROWNUM RN FROM table1 ORDER BY a ASC) at WHERE at.RN<=5", sql)
+	assert.EqualValues(t, "SELECT * FROM (SELECT a,b,c FROM table1 ORDER BY a ASC) at WHERE ROWNUM<=5", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// simple with where -- OracleSQL style
 	sql, err = Dialect(ORACLE).Select("a", "b", "c").From("table1").Where(Neq{"a": "10", "b": "20"}).
 		OrderBy("a ASC").Limit(5, 1).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT * FROM (SELECT a,b,c,ROWNUM RN FROM table1 WHERE a<>'10' AND b<>'20' ORDER BY a ASC) at WHERE at.RN<=6) att WHERE att.RN>1", sql)
+	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT at.*,ROWNUM RN FROM (SELECT a,b,c FROM table1 WHERE a<>'10' AND b<>'20' ORDER BY a ASC) at WHERE ROWNUM<=6) att WHERE att.RN>1", sql)
 	assert.NoError(t, f.executableCheck(sql))
 	// union with limit -- OracleSQL style
 	sql, err = Dialect(ORACLE).Select("a", "b", "c").From(
@@ -126,6 +126,61 @@ func TestBuilder_Limit4Oracle(t *testing.T) {
 				OrderBy("a DESC").Limit(10)), "at").
 		Limit(3).ToBoundSQL()
 	assert.NoError(t, err)
-	assert.EqualValues(t, "SELECT a,b,c FROM (SELECT a,b,c,ROWNUM RN FROM ((SELECT a,b,c FROM (SELECT * FROM (SELECT a,b,c,ROWNUM RN FROM table1 WHERE a<>'0' ORDER BY a ASC) at WHERE at.RN<=15) att WHERE att.RN>10) UNION ALL (SELECT a,b,c FROM (SELECT a,b,c,ROWNUM RN FROM table1 WHERE b<>'48' ORDER BY a DESC) at WHERE at.RN<=10)) at) at WHERE at.RN<=3", sql)
+	assert.EqualValues(t, "SELECT * FROM (SELECT a,b,c FROM ((SELECT a,b,c FROM (SELECT at.*,ROWNUM RN FROM (SELECT a,b,c FROM table1 WHERE a<>'0' ORDER BY a ASC) at WHERE ROWNUM<=15) att WHERE att.RN>10) UNION ALL (SELECT * FROM (SELECT a,b,c FROM table1 WHERE b<>'48' ORDER BY a DESC) at WHERE ROWNUM<=10)) at) at WHERE ROWNUM<=3", sql)
 	assert.NoError(t, f.executableCheck(sql))
 }
+
+func TestBuilder_LimitOffsetFetch(t *testing.T) {
+	sql, args, err := Dialect(MSSQL2012).Select("a", "b").From("table1").Where(Neq{"a": 3}).
+		OrderBy("a ASC").Limit(5, 10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a,b FROM table1 WHERE a<>@p1 ORDER BY a ASC OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY", sql)
+	assert.Len(t, args, 1)
+
+	sql, err = Dialect(MSSQL2012).Select("a").From("table1").Limit(5).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", sql)
+
+	sql, err = Dialect(ORACLE12).Select("a").From("table1").Limit(5).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", sql)
+
+	sql, err = Dialect(ORACLE12).Select("a").From(Dialect(ORACLE12).Select("a").From("table1").
+		OrderBy(Desc("a")).Limit(3, 6), "t").Where(Gt{"a": 1}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM (SELECT a FROM table1 ORDER BY a DESC OFFSET 6 ROWS FETCH NEXT 3 ROWS ONLY) t WHERE a>1", sql)
+}
+
+func TestBuilder_LimitLegacyOrder(t *testing.T) {
+	// the order of the query numbers the rows, binding its args once more
+	sql, args, err := MsSQL().Select("t.a", "b AS x", "count(*) AS n").From("table1 t").GroupBy("t.a").GroupBy("b").
+		OrderBy("abs(t.a-?)", 4).Limit(5, 10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 a,x,n FROM (SELECT t.a,b AS x,count(*) AS n,ROW_NUMBER() OVER (ORDER BY abs(t.a-@p1)) AS RN FROM table1 t GROUP BY t.a,b) at WHERE at.RN>@p2 ORDER BY at.RN ASC", sql)
+	assert.Len(t, args, 2)
+
+	// NULLS FIRST and NULLS LAST are emulated within ROW_NUMBER() as well
+	sql, err = MsSQL().Select("a").From("t").OrderBy(Asc("a").NullsLast()).OrderBy(Desc("b").NullsFirst()).Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 a FROM (SELECT a,ROW_NUMBER() OVER (ORDER BY CASE WHEN a IS NULL THEN 1 ELSE 0 END,a ASC,CASE WHEN b IS NULL THEN 0 ELSE 1 END,b DESC) AS RN FROM t) at WHERE at.RN>10 ORDER BY at.RN ASC", sql)
+
+	// aliases are known outside of the query only, so are the distinct rows
+	sql, err = MsSQL().Select("a", "count(*) AS n").From("t").GroupBy("a").OrderBy("n DESC").Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 a,n FROM (SELECT at.*,ROW_NUMBER() OVER (ORDER BY n DESC) AS RN FROM (SELECT a,count(*) AS n FROM t GROUP BY a) at) att WHERE att.RN>10 ORDER BY att.RN ASC", sql)
+	sql, err = MsSQL().Select("DISTINCT a", "b").From("t").OrderBy(Asc("a")).Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 a,b FROM (SELECT at.*,ROW_NUMBER() OVER (ORDER BY a ASC) AS RN FROM (SELECT DISTINCT a,b FROM t) at) att WHERE att.RN>10 ORDER BY att.RN ASC", sql)
+	sql, err = MsSQL().Select("DISTINCT a").From("t").OrderBy(Asc("a")).Limit(5).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT DISTINCT TOP 5 a FROM t ORDER BY a ASC", sql)
+
+	// columns without a name could only be selected along with the RN column
+	_, err = Oracle().Select("a", "a+1").From("table1").Limit(5, 10).ToBoundSQL()
+	assert.EqualValues(t, ErrUnnamedPagedColumn, err)
+	_, err = MsSQL().Select("a").SelectExpr(WindowFunc("RANK()").OrderBy("a")).From("table1").Limit(5, 10).ToBoundSQL()
+	assert.EqualValues(t, ErrUnnamedPagedColumn, err)
+	sql, err = Oracle().Select("t.*").From("table1 t").Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT * FROM (SELECT at.*,ROWNUM RN FROM (SELECT t.* FROM table1 t) at WHERE ROWNUM<=15) att WHERE att.RN>10", sql)
+}
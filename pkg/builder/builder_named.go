@@ -0,0 +1,120 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"reflect"
+
+	"github.com/bhojpur/sql/pkg/sqllex"
+)
+
+// NamedArgs binds the sql.NamedArg arguments of the statement by their names, e.g. Eq{"id": sql.Named("uid", 5)}.
+// ToSQL writes them as :uid for Oracle or @uid for SQL Server and returns them as they are. Dialects binding
+// by position get them as values, written once as $1 for PostgreSQL or as often as they are used as ? for MySQL
+// and SQLite. Other arguments are written as without NamedArgs.
+func (b *Builder) NamedArgs() *Builder {
+	b.namedArgs = true
+	return b
+}
+
+// binding is the position and value of a named argument bound by bindNamedArgs
+type binding struct {
+	n     int
+	value interface{}
+}
+
+// bindNamedArgs replaces the ? of sql by the placeholders of dialect d, binding the arguments named alike once
+// if d names or numbers its placeholders
+func bindNamedArgs(sql string, args []interface{}, d SQLDialect) (string, []interface{}, error) {
+	var (
+		syntax   = sqllex.Generic
+		bound    = make([]interface{}, 0, len(args))
+		bindings = make(map[string]binding)
+		binder   NamedBinder
+		numbered bool
+		i        int
+		err      error
+	)
+	if d != nil {
+		binder, _ = d.(NamedBinder)
+		syntax = syntaxOf(d)
+		numbered = d.Placeholder(1) != d.Placeholder(2)
+	}
+	// seen reports whether an argument named name has been bound, failing if it has another value
+	seen := func(name string, value interface{}) (binding, bool) {
+		b, ok := bindings[name]
+		if ok && !reflect.DeepEqual(b.value, value) {
+			err = ErrConflictingNamedArg
+		}
+		return b, ok
+	}
+	sql, convErr := convertPlaceholder(sql, syntax, func(int) string {
+		if i == len(args) {
+			err = ErrNeedMoreArguments
+			return "?"
+		}
+		arg := args[i]
+		i++
+		namedArg, isNamed := arg.(sql2.NamedArg)
+		isNamed = isNamed && namedArg.Name != ""
+		if isNamed && binder != nil {
+			if placeholder := binder.NamedPlaceholder(namedArg.Name); placeholder != "" {
+				if _, ok := seen(namedArg.Name, namedArg.Value); !ok {
+					bindings[namedArg.Name] = binding{len(bound) + 1, namedArg.Value}
+					bound = append(bound, namedArg)
+				}
+				return placeholder
+			}
+		}
+		if namedArg, ok := arg.(sql2.NamedArg); ok {
+			arg = namedArg.Value
+		}
+		if d == nil {
+			bound = append(bound, arg)
+			return "?"
+		}
+		if isNamed && numbered {
+			if b, ok := seen(namedArg.Name, arg); ok {
+				return d.Placeholder(b.n)
+			}
+			bindings[namedArg.Name] = binding{len(bound) + 1, arg}
+		}
+		n := len(bound) + 1
+		boundArg := d.BindArg(n, arg)
+		// the name a dialect gives to an argument must not be taken
+		if generated, ok := boundArg.(sql2.NamedArg); ok {
+			if _, taken := bindings[generated.Name]; taken {
+				err = ErrConflictingNamedArg
+			}
+			bindings[generated.Name] = binding{n, arg}
+		}
+		bound = append(bound, boundArg)
+		return d.Placeholder(n)
+	})
+	if convErr != nil {
+		return "", nil, convErr
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, bound, nil
+}
@@ -0,0 +1,99 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_NamedArgs(t *testing.T) {
+	users := func(d string) *Builder {
+		return Dialect(d).NamedArgs().Select("id").From("users").
+			Where(Eq{"owner_id": sql2.Named("uid", 5)}.Or(Eq{"creator_id": sql2.Named("uid", 5)})).
+			And(Gt{"age": 18})
+	}
+
+	sql, args, err := users(ORACLE).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE (owner_id=:uid OR creator_id=:uid) AND age>:p2", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("uid", 5), sql2.Named("p2", 18)}, args)
+
+	sql, args, err = users(MSSQL).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE (owner_id=@uid OR creator_id=@uid) AND age>@p2", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("uid", 5), sql2.Named("p2", 18)}, args)
+
+	sql, args, err = users(POSTGRES).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE (owner_id=$1 OR creator_id=$1) AND age>$2", sql)
+	assert.EqualValues(t, []interface{}{5, 18}, args)
+
+	sql, args, err = users(MYSQL).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE (owner_id=? OR creator_id=?) AND age>?", sql)
+	assert.EqualValues(t, []interface{}{5, 5, 18}, args)
+
+	sql, args, err = Select("id").From("users").NamedArgs().Where(Eq{"id": sql2.Named("uid", 5)}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE id=?", sql)
+	assert.EqualValues(t, []interface{}{5}, args)
+
+	// named arguments survive the wrapping of a paged query and union members
+	sql, args, err = Oracle().NamedArgs().Select("id").From("users").Where(Eq{"team": sql2.Named("team", 3)}).
+		Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT * FROM (SELECT id FROM users WHERE team=:team) at WHERE ROWNUM<=:p2", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("team", 3), sql2.Named("p2", 10)}, args)
+
+	sql, args, err = Postgres().NamedArgs().Select("id").From("a").Where(Eq{"team": sql2.Named("team", 3)}).
+		Union("ALL", Select("id").From("b").Where(Eq{"team": sql2.Named("team", 3)})).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "(SELECT id FROM a WHERE team=$1) UNION ALL (SELECT id FROM b WHERE team=$1)", sql)
+	assert.EqualValues(t, []interface{}{3}, args)
+
+	// without NamedArgs names are dropped
+	sql, args, err = MsSQL().Select("id").From("users").Where(Eq{"id": sql2.Named("uid", 5)}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM users WHERE id=@p1", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("p1", 5)}, args)
+}
+
+func TestBuilder_NamedArgsConflict(t *testing.T) {
+	for _, d := range []string{ORACLE, POSTGRES} {
+		_, _, err := Dialect(d).NamedArgs().Select("id").From("users").
+			Where(Eq{"a": sql2.Named("v", 1), "b": sql2.Named("v", 2)}).ToSQL()
+		assert.EqualValues(t, ErrConflictingNamedArg, err, d)
+	}
+
+	// MySQL binds each use of a name on its own
+	_, args, err := MySQL().NamedArgs().Select("id").From("users").
+		Where(Eq{"a": sql2.Named("v", 1), "b": sql2.Named("v", 2)}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{1, 2}, args)
+
+	// the names given to unnamed arguments are taken
+	_, _, err = MsSQL().NamedArgs().Select("id").From("users").
+		Where(Eq{"a": 1}.And(Eq{"b": sql2.Named("p1", 2)})).ToSQL()
+	assert.EqualValues(t, ErrConflictingNamedArg, err)
+}
@@ -0,0 +1,98 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+)
+
+// Order is a column the rows of a select are ordered by, see Asc and Desc
+type Order struct {
+	col   string
+	desc  bool
+	nulls string
+}
+
+var _ Cond = &Order{}
+
+// Asc orders by col in ascending order
+func Asc(col string) *Order {
+	return &Order{col: col}
+}
+
+// Desc orders by col in descending order
+func Desc(col string) *Order {
+	return &Order{col: col, desc: true}
+}
+
+// NullsFirst returns a copy of the order putting the rows whose col is NULL first. Dialects
+// without NULLS FIRST get it emulated by a CASE expression.
+func (o *Order) NullsFirst() *Order {
+	c := *o
+	c.nulls = "FIRST"
+	return &c
+}
+
+// NullsLast returns a copy of the order putting the rows whose col is NULL last. Dialects
+// without NULLS LAST get it emulated by a CASE expression.
+func (o *Order) NullsLast() *Order {
+	c := *o
+	c.nulls = "LAST"
+	return &c
+}
+
+// WriteTo implements Cond
+func (o *Order) WriteTo(w Writer) error {
+	col := quoteIdent(w, o.col)
+	dir := "ASC"
+	if o.desc {
+		dir = "DESC"
+	}
+	if o.nulls == "" {
+		_, err := fmt.Fprintf(w, "%s %s", col, dir)
+		return err
+	}
+	if d := writerDialect(w); d != nil && !d.Supports(FeatureNullsOrdering) {
+		isNull, notNull := 1, 0
+		if o.nulls == "FIRST" {
+			isNull, notNull = 0, 1
+		}
+		_, err := fmt.Fprintf(w, "CASE WHEN %s IS NULL THEN %d ELSE %d END,%s %s", col, isNull, notNull, col, dir)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %s NULLS %s", col, dir, o.nulls)
+	return err
+}
+
+// And implements Cond
+func (o *Order) And(conds ...Cond) Cond {
+	return And(o, And(conds...))
+}
+
+// Or implements Cond
+func (o *Order) Or(conds ...Cond) Cond {
+	return Or(o, Or(conds...))
+}
+
+// IsValid implements Cond
+func (o *Order) IsValid() bool {
+	return len(o.col) > 0
+}
@@ -0,0 +1,65 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_OrderBy(t *testing.T) {
+	sql, args, err := Select("a").From("table1").OrderBy("a ASC").OrderBy("abs(b-?)", 5).
+		OrderBy(Desc("c")).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 ORDER BY a ASC,abs(b-?),c DESC", sql)
+	assert.EqualValues(t, []interface{}{5}, args)
+
+	sql, err = Postgres().Select("a").From("table1").OrderByExpr(Asc("a").NullsFirst(), Desc("b").NullsLast()).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 ORDER BY a ASC NULLS FIRST,b DESC NULLS LAST", sql)
+
+	sql, err = MySQL().QuoteIdents().Select("a").From("table1").OrderByExpr(Asc("a").NullsLast(), Desc("t.b").NullsFirst()).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT `a` FROM `table1` ORDER BY CASE WHEN `a` IS NULL THEN 1 ELSE 0 END,`a` ASC,CASE WHEN `t`.`b` IS NULL THEN 0 ELSE 1 END,`t`.`b` DESC", sql)
+
+	sql, err = MsSQL().Select("a").From("table1").OrderBy(Asc("a").NullsLast()).Limit(5).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 a FROM table1 ORDER BY CASE WHEN a IS NULL THEN 1 ELSE 0 END,a ASC", sql)
+}
+
+func TestBuilder_GroupByHaving(t *testing.T) {
+	sql, args, err := Postgres().Select("dept", "count(*)").From("employee").Where(Eq{"active": true}).
+		GroupBy("dept").GroupBy("date_trunc(?, hired)", "year").
+		Having("count(*) > ?", 10).Having(Lt{"max(salary)": 1000}).
+		OrderBy("abs(avg(salary)-?)", 500).Limit(3).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT dept,count(*) FROM employee WHERE active=$1 GROUP BY dept,date_trunc($2, hired) HAVING (count(*) > $3) AND max(salary)<$4 ORDER BY abs(avg(salary)-$5) LIMIT 3", sql)
+	assert.EqualValues(t, []interface{}{true, "year", 10, 1000, 500}, args)
+
+	sql, args, err = Select("dept").From("employee").GroupBy("dept").Having("count(*) > ?", 1).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT dept FROM employee GROUP BY dept HAVING count(*) > ?", sql)
+	assert.EqualValues(t, []interface{}{1}, args)
+
+	_, _, err = Select("a").From("table1").Union("ALL", Select("a").From("table2")).Having("count(*) > 1").ToSQL()
+	assert.EqualValues(t, ErrNotUnexpectedUnionConditions, err)
+}
@@ -0,0 +1,116 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"fmt"
+)
+
+// Returning sets the columns returned by an insert, update or delete. PostgreSQL and SQLite
+// get a RETURNING clause and SQL Server an OUTPUT clause of the INSERTED or DELETED columns.
+// Oracle returns the columns of a single row INTO out-binds, which are appended to the args
+// as sql.Out whose Dest points to an interface{}. MySQL cannot return columns.
+func (b *Builder) Returning(cols ...string) *Builder {
+	b.returning = cols
+	return b
+}
+
+// returningStyle returns the feature used to return the columns or 0 if there are none
+func (b *Builder) returningStyle() (Feature, error) {
+	if len(b.returning) == 0 {
+		return 0, nil
+	}
+	d, err := b.requireDialect()
+	if err != nil {
+		return 0, err
+	}
+	for _, style := range []Feature{FeatureReturning, FeatureOutput, FeatureReturningInto} {
+		if d.Supports(style) {
+			return style, nil
+		}
+	}
+	return 0, ErrNotSupportReturning
+}
+
+// outputWriteTo writes the OUTPUT clause of the columns of table, which is either INSERTED or DELETED
+func (b *Builder) outputWriteTo(w Writer, table string) error {
+	style, err := b.returningStyle()
+	if err != nil || style != FeatureOutput {
+		return err
+	}
+	if _, err := fmt.Fprint(w, " OUTPUT "); err != nil {
+		return err
+	}
+	for i, col := range b.returning {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, table, ".", quoteIdent(w, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returningWriteTo writes the RETURNING clause, if the dialect has one
+func (b *Builder) returningWriteTo(w Writer) error {
+	style, err := b.returningStyle()
+	if err != nil || (style != FeatureReturning && style != FeatureReturningInto) {
+		return err
+	}
+	if _, err := fmt.Fprint(w, " RETURNING "); err != nil {
+		return err
+	}
+	for i, col := range b.returning {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if style == FeatureReturningInto && col == "*" {
+			return ErrNotSupportReturning
+		}
+		if _, err := fmt.Fprint(w, quoteIdent(w, col)); err != nil {
+			return err
+		}
+	}
+	if style != FeatureReturningInto {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, " INTO "); err != nil {
+		return err
+	}
+	for i := range b.returning {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, "?"); err != nil {
+			return err
+		}
+		w.Append(sql2.Out{Dest: new(interface{})})
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_ReturningClause(t *testing.T) {
+	sql, args, err := Postgres().Insert(Eq{"name": "cat"}).Into("table1").Returning("id", "created").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (name) Values ($1) RETURNING id,created", sql)
+	assert.EqualValues(t, []interface{}{"cat"}, args)
+
+	sql, err = SQLite().InsertRows(Eq{"id": 1}, Eq{"id": 2}).Into("table1").OnConflict("id").Returning("*").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id) Values (1),(2) ON CONFLICT (id) DO NOTHING RETURNING *", sql)
+
+	sql, err = Postgres().Insert("a").Into("table1").Select("a").From("table2").Returning("id").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (a) SELECT a FROM table2 RETURNING id", sql)
+
+	sql, err = Postgres().QuoteIdents().Update(Eq{"name": "cat"}).From("user").Where(Eq{"id": 1}).Returning("id", "order").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `UPDATE "user" SET "name"='cat' WHERE "id"=1 RETURNING "id","order"`, sql)
+
+	sql, err = SQLite().Delete(Eq{"id": 1}).From("table1").Returning("id").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "DELETE FROM table1 WHERE id=1 RETURNING id", sql)
+}
+
+func TestBuilder_ReturningOutput(t *testing.T) {
+	sql, args, err := MsSQL().Insert(Eq{"name": "cat"}).Into("table1").Returning("id", "name").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (name) OUTPUT INSERTED.id,INSERTED.name Values (@p1)", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("p1", "cat")}, args)
+
+	sql, err = MsSQL().Insert().Into("table1").Select().From("table2").Returning("*").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 OUTPUT INSERTED.* SELECT * FROM table2", sql)
+
+	sql, err = MsSQL().QuoteIdents().Update(Eq{"name": "cat"}).From("user").Where(Eq{"id": 1}).Returning("name").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "UPDATE [user] SET [name]='cat' OUTPUT INSERTED.[name] WHERE [id]=1", sql)
+
+	sql, err = MsSQL().Delete(Eq{"id": 1}).From("table1").Returning("*").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "DELETE FROM table1 OUTPUT DELETED.* WHERE id=1", sql)
+
+	sql, err = MsSQL().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").OnConflict("id").Returning("id").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "MERGE INTO table1 WITH (HOLDLOCK) target USING (SELECT 1 id,'cat' name) excluded ON (target.id=excluded.id) WHEN NOT MATCHED THEN INSERT (id,name) VALUES (excluded.id,excluded.name) OUTPUT INSERTED.id;", sql)
+}
+
+func TestBuilder_ReturningInto(t *testing.T) {
+	sql, args, err := Oracle().Insert(Eq{"name": "cat"}).Into("table1").Returning("id", "created").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (name) Values (:p1) RETURNING id,created INTO :p2,:p3", sql)
+	if assert.Len(t, args, 3) {
+		assert.EqualValues(t, sql2.Named("p1", "cat"), args[0])
+		out, ok := args[2].(sql2.NamedArg).Value.(sql2.Out)
+		assert.True(t, ok)
+		assert.NotNil(t, out.Dest)
+	}
+
+	sql, args, err = Oracle().Update(Eq{"name": "cat"}).From("table1").Where(Eq{"id": 1}).Returning("name").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "UPDATE table1 SET name=:p1 WHERE id=:p2 RETURNING name INTO :p3", sql)
+	assert.Len(t, args, 3)
+
+	// the columns are returned into output arguments, which cannot be written inline
+	_, err = Oracle().Insert(Eq{"name": "cat"}).Into("table1").Returning("id").ToBoundSQL()
+	assert.EqualValues(t, ErrBoundOutArg, err)
+
+	_, _, err = Oracle().Delete(Eq{"id": 1}).From("table1").Returning("*").ToSQL()
+	assert.EqualValues(t, ErrNotSupportReturning, err)
+	_, _, err = Oracle().InsertRows(Eq{"id": 1}, Eq{"id": 2}).Into("table1").Returning("id").ToSQL()
+	assert.EqualValues(t, ErrNotSupportReturning, err)
+	_, _, err = Oracle().Insert(Eq{"id": 1}).Into("table1").OnConflict("id").Returning("id").ToSQL()
+	assert.EqualValues(t, ErrNotSupportReturning, err)
+}
+
+func TestBuilder_ReturningErrors(t *testing.T) {
+	_, _, err := MySQL().Insert(Eq{"name": "cat"}).Into("table1").Returning("id").ToSQL()
+	assert.EqualValues(t, ErrNotSupportReturning, err)
+	_, _, err = MySQL().Delete(Eq{"id": 1}).From("table1").Returning("id").ToSQL()
+	assert.EqualValues(t, ErrNotSupportReturning, err)
+	_, _, err = Update(Eq{"name": "cat"}).From("table1").Returning("id").ToSQL()
+	assert.EqualValues(t, ErrDialectNotSetUp, err)
+}
@@ -33,32 +33,68 @@ func (b *Builder) selectWriteTo(w Writer) error {
 	if len(b.from) <= 0 && !b.isNested {
 		return ErrNoTableName
 	}
-	// perform limit before writing to writer when b.dialect between ORACLE and MSSQL
+	// perform limit before writing to writer when the dialect wraps the query to paginate it,
 	// this avoid a duplicate writing problem in simple limit query
-	if b.limitation != nil && (b.dialect == ORACLE || b.dialect == MSSQL) {
-		return b.limitWriteTo(w)
+	if b.limitation != nil {
+		if d := GetDialect(b.dialect); d != nil && (d.Pagination() == PaginationRowNum || d.Pagination() == PaginationTopRowNumber) {
+			return b.limitWriteTo(w)
+		}
+	}
+	if len(b.windows) > 0 && !b.supports(FeatureWindowClause) {
+		ww := withDialect(w, GetDialect(b.dialect))
+		ww.windows = b.windows
+		w = ww
 	}
 	if _, err := fmt.Fprint(w, "SELECT "); err != nil {
 		return err
 	}
-	if len(b.selects) > 0 {
-		for i, s := range b.selects {
-			if _, err := fmt.Fprint(w, s); err != nil {
+	selects := b.selects
+	if b.limitTop > 0 {
+		// TOP follows DISTINCT
+		if b.isDistinct() {
+			selects = append(selects[:0:0], selects...)
+			selects[0] = distinctPrefix.ReplaceAllString(selects[0], "")
+			if _, err := fmt.Fprint(w, "DISTINCT "); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "TOP %d ", b.limitTop); err != nil {
+			return err
+		}
+	}
+	if len(selects) > 0 || len(b.selectExprs) > 0 {
+		for i, s := range selects {
+			if _, err := fmt.Fprint(w, quoteIdent(w, s)); err != nil {
 				return err
 			}
-			if i != len(b.selects)-1 {
+			if i != len(selects)-1 {
+				if _, err := fmt.Fprint(w, ","); err != nil {
+					return err
+				}
+			}
+		}
+		for i, e := range b.selectExprs {
+			if i > 0 || len(selects) > 0 {
 				if _, err := fmt.Fprint(w, ","); err != nil {
 					return err
 				}
 			}
+			if err := selectExprWriteTo(w, e); err != nil {
+				return err
+			}
 		}
 	} else {
 		if _, err := fmt.Fprint(w, "*"); err != nil {
 			return err
 		}
 	}
+	if b.limitRowNumber {
+		if err := b.rowNumberWriteTo(w); err != nil {
+			return err
+		}
+	}
 	if b.subQuery == nil {
-		if _, err := fmt.Fprint(w, " FROM ", b.from); err != nil {
+		if _, err := fmt.Fprint(w, " FROM ", quoteIdent(w, b.from)); err != nil {
 			return err
 		}
 	} else {
@@ -69,19 +105,17 @@ func (b *Builder) selectWriteTo(w Writer) error {
 			return ErrInconsistentDialect
 		}
 		// dialect of sub-query will inherit from the main one (if not set up)
-		if b.dialect != "" && b.subQuery.dialect == "" {
-			b.subQuery.dialect = b.dialect
-		}
-		switch b.subQuery.optype {
+		subQuery := b.subQuery.inheritDialect(b.dialect)
+		switch subQuery.optype {
 		case selectType, setOpType:
 			fmt.Fprint(w, " FROM (")
-			if err := b.subQuery.WriteTo(w); err != nil {
+			if err := subQuery.WriteTo(w); err != nil {
 				return err
 			}
 			if len(b.from) == 0 {
 				fmt.Fprintf(w, ")")
 			} else {
-				fmt.Fprintf(w, ") %v", b.from)
+				fmt.Fprintf(w, ") %v", quoteIdent(w, b.from))
 			}
 		default:
 			return ErrUnexpectedSubQuery
@@ -99,6 +133,10 @@ func (b *Builder) selectWriteTo(w Writer) error {
 			if _, err := fmt.Fprintf(w, ") ON "); err != nil {
 				return err
 			}
+		} else if table, ok := v.joinTable.(string); ok {
+			if _, err := fmt.Fprintf(w, " %s JOIN %s ON ", v.joinType, quoteIdent(w, table)); err != nil {
+				return err
+			}
 		} else {
 			if _, err := fmt.Fprintf(w, " %s JOIN %s ON ", v.joinType, v.joinTable); err != nil {
 				return err
@@ -108,26 +146,48 @@ func (b *Builder) selectWriteTo(w Writer) error {
 			return err
 		}
 	}
-	if b.cond.IsValid() {
+	cond := b.cond
+	if b.keyset != nil {
+		kc, err := b.keysetCond()
+		if err != nil {
+			return err
+		}
+		if cond.IsValid() {
+			cond = And(cond, kc)
+		} else {
+			cond = kc
+		}
+	}
+	if cond.IsValid() {
 		if _, err := fmt.Fprint(w, " WHERE "); err != nil {
 			return err
 		}
-		if err := b.cond.WriteTo(w); err != nil {
+		if err := cond.WriteTo(w); err != nil {
 			return err
 		}
 	}
-	if len(b.groupBy) > 0 {
-		if _, err := fmt.Fprint(w, " GROUP BY ", b.groupBy); err != nil {
+	if err := writeList(w, " GROUP BY ", b.groupBy); err != nil {
+		return err
+	}
+	if b.having != nil && b.having.IsValid() {
+		if _, err := fmt.Fprint(w, " HAVING "); err != nil {
 			return err
 		}
-	}
-	if len(b.having) > 0 {
-		if _, err := fmt.Fprint(w, " HAVING ", b.having); err != nil {
+		if err := b.having.WriteTo(w); err != nil {
 			return err
 		}
 	}
-	if len(b.orderBy) > 0 {
-		if _, err := fmt.Fprint(w, " ORDER BY ", b.orderBy); err != nil {
+	if err := b.windowsWriteTo(w); err != nil {
+		return err
+	}
+	ow := w
+	if d := GetDialect(b.dialect); d != nil {
+		// orders by Asc and Desc emulate NULLS FIRST and NULLS LAST if the dialect has none
+		ow = withDialect(w, d)
+	}
+	if !b.limitRowNumber {
+		// otherwise the rows are ordered by their ROW_NUMBER() by the wrapping query
+		if err := writeList(ow, " ORDER BY ", b.orderBy); err != nil {
 			return err
 		}
 	}
@@ -139,20 +199,67 @@ func (b *Builder) selectWriteTo(w Writer) error {
 	return nil
 }
 
-// OrderBy orderBy SQL
-func (b *Builder) OrderBy(orderBy string) *Builder {
-	b.orderBy = orderBy
+// OrderBy adds a term the rows are ordered by. It is either SQL like "a ASC, b DESC" whose
+// placeholders are bound to args, a column ordered by Asc or Desc or any other Cond.
+func (b *Builder) OrderBy(orderBy interface{}, args ...interface{}) *Builder {
+	if expr := toExpr(orderBy, args); expr != nil {
+		b.orderBy = append(b.orderBy, expr)
+	}
 	return b
 }
 
-// GroupBy groupby SQL
-func (b *Builder) GroupBy(groupby string) *Builder {
-	b.groupBy = groupby
+// GroupBy adds an expression the rows are grouped by. It is either SQL whose placeholders
+// are bound to args or a Cond.
+func (b *Builder) GroupBy(groupBy interface{}, args ...interface{}) *Builder {
+	if expr := toExpr(groupBy, args); expr != nil {
+		b.groupBy = append(b.groupBy, expr)
+	}
 	return b
 }
 
-// Having having SQL
-func (b *Builder) Having(having string) *Builder {
-	b.having = having
+// Having adds a condition on the groups, combined with the previous ones by AND. It is
+// either SQL like "count(*) > ?" whose placeholders are bound to args or a Cond.
+func (b *Builder) Having(having interface{}, args ...interface{}) *Builder {
+	if expr := toExpr(having, args); expr != nil {
+		if b.having == nil {
+			b.having = expr
+		} else {
+			b.having = And(b.having, expr)
+		}
+	}
 	return b
 }
+
+// toExpr converts SQL with its args or a Cond to a Cond
+func toExpr(v interface{}, args []interface{}) Cond {
+	switch t := v.(type) {
+	case string:
+		if t != "" {
+			return Expr(t, args...)
+		}
+	case Cond:
+		return t
+	}
+	return nil
+}
+
+// writeList writes the expressions separated by commas after prefix, or nothing without expressions
+func writeList(w Writer, prefix string, exprs []Cond) error {
+	if len(exprs) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, prefix); err != nil {
+		return err
+	}
+	for i, e := range exprs {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := e.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -26,8 +26,13 @@ import (
 )
 
 func (b *Builder) setOpWriteTo(w Writer) error {
+	return b.setOpMembersWriteTo(w, true)
+}
+
+// setOpMembersWriteTo writes the members of a set operation, in parentheses if there is more than one and parens is set
+func (b *Builder) setOpMembersWriteTo(w Writer, parens bool) error {
 	if b.limitation != nil || b.cond.IsValid() ||
-		b.orderBy != "" || b.having != "" || b.groupBy != "" {
+		len(b.orderBy) > 0 || b.having != nil || len(b.groupBy) > 0 {
 		return ErrNotUnexpectedUnionConditions
 	}
 	for idx, o := range b.setOps {
@@ -39,23 +44,31 @@ func (b *Builder) setOpWriteTo(w Writer) error {
 			if err := current.selectWriteTo(w); err != nil {
 				return err
 			}
-		} else {
-			if b.dialect != "" && b.dialect != current.dialect {
-				return ErrInconsistentDialect
-			}
-			if idx != 0 {
-				if o.distinctType == "" {
-					fmt.Fprint(w, fmt.Sprintf(" %s ", strings.ToUpper(o.opType)))
-				} else {
-					fmt.Fprint(w, fmt.Sprintf(" %s %s ", strings.ToUpper(o.opType), strings.ToUpper(o.distinctType)))
-				}
-			}
+			continue
+		}
+		if b.dialect != "" && b.dialect != current.dialect {
+			return ErrInconsistentDialect
+		}
+		if idx != 0 {
+			writeSetOp(w, o)
+		}
+		if parens {
 			fmt.Fprint(w, "(")
-			if err := current.selectWriteTo(w); err != nil {
-				return err
-			}
+		}
+		if err := current.selectWriteTo(w); err != nil {
+			return err
+		}
+		if parens {
 			fmt.Fprint(w, ")")
 		}
 	}
 	return nil
 }
+
+func writeSetOp(w Writer, o setOp) {
+	if o.distinctType == "" {
+		fmt.Fprint(w, fmt.Sprintf(" %s ", strings.ToUpper(o.opType)))
+	} else {
+		fmt.Fprint(w, fmt.Sprintf(" %s %s ", strings.ToUpper(o.opType), strings.ToUpper(o.distinctType)))
+	}
+}
@@ -42,24 +42,25 @@ func (b *Builder) updateWriteTo(w Writer) error {
 	if len(b.updates) <= 0 {
 		return ErrNoColumnToUpdate
 	}
-	if _, err := fmt.Fprintf(w, "UPDATE %s SET ", b.from); err != nil {
+	if _, err := b.returningStyle(); err != nil {
 		return err
 	}
-	for i, s := range b.updates {
-		if err := s.OpWriteTo(",", w); err != nil {
-			return err
-		}
-		if i != len(b.updates)-1 {
-			if _, err := fmt.Fprint(w, ","); err != nil {
-				return err
-			}
-		}
+	if _, err := fmt.Fprintf(w, "UPDATE %s SET ", quoteIdent(w, b.from)); err != nil {
+		return err
 	}
-	if !b.cond.IsValid() {
-		return nil
+	if err := writeUpdates(w, b.updates); err != nil {
+		return err
 	}
-	if _, err := fmt.Fprint(w, " WHERE "); err != nil {
+	if err := b.outputWriteTo(w, "INSERTED"); err != nil {
 		return err
 	}
-	return b.cond.WriteTo(w)
+	if b.cond.IsValid() {
+		if _, err := fmt.Fprint(w, " WHERE "); err != nil {
+			return err
+		}
+		if err := b.cond.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return b.returningWriteTo(w)
 }
@@ -0,0 +1,253 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+)
+
+// Excluded references the value a column would have been inserted with. It is used to
+// update the conflicting rows of an upsert, e.g. DoUpdate(Eq{"b": Excluded("b")})
+type Excluded string
+
+type upsert struct {
+	cols    []string
+	update  bool
+	updates []UpdateCond
+}
+
+// OnConflict turns an insert into an upsert of the rows conflicting on cols.
+// MySQL finds the conflicting rows by any unique key and ignores cols.
+// Without DoUpdate conflicting rows are left as they are.
+func (b *Builder) OnConflict(cols ...string) *Builder {
+	b.upsert = &upsert{cols: cols}
+	return b
+}
+
+// DoNothing leaves the rows conflicting with an upsert as they are
+func (b *Builder) DoNothing() *Builder {
+	if b.upsert == nil {
+		b.upsert = &upsert{}
+	}
+	b.upsert.update = false
+	b.upsert.updates = nil
+	return b
+}
+
+// DoUpdate updates the rows conflicting with an upsert. Without updates every inserted
+// column apart from the conflict target is set to the value it would have been inserted with.
+func (b *Builder) DoUpdate(updates ...Cond) *Builder {
+	if b.upsert == nil {
+		b.upsert = &upsert{}
+	}
+	b.upsert.update = true
+	b.upsert.updates = make([]UpdateCond, 0, len(updates))
+	for _, update := range updates {
+		if u, ok := update.(UpdateCond); ok && u.IsValid() {
+			b.upsert.updates = append(b.upsert.updates, u)
+		}
+	}
+	return b
+}
+
+// upsertUpdates returns the updates of conflicting rows, none if they are left as they are
+func (b *Builder) upsertUpdates() []UpdateCond {
+	if !b.upsert.update || len(b.upsert.updates) > 0 {
+		return b.upsert.updates
+	}
+	target := make(map[string]bool, len(b.upsert.cols))
+	for _, col := range b.upsert.cols {
+		target[col] = true
+	}
+	eq := Eq{}
+	for _, col := range b.insertCols {
+		if !target[col] {
+			eq[col] = Excluded(col)
+		}
+	}
+	if len(eq) == 0 {
+		return nil
+	}
+	return []UpdateCond{eq}
+}
+
+// excludedRef returns the reference to the value col would have been inserted with
+func excludedRef(w Writer, col string) (string, error) {
+	if dw, ok := w.(*dialectWriter); ok && dw.excluded != nil {
+		return dw.excluded(col), nil
+	}
+	return "", ErrUnexpectedExcluded
+}
+
+func writeUpdates(w Writer, updates []UpdateCond) error {
+	for i, u := range updates {
+		if err := u.OpWriteTo(",", w); err != nil {
+			return err
+		}
+		if i != len(updates)-1 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Builder) upsertWriteTo(w Writer) error {
+	d, err := b.requireDialect()
+	if err != nil {
+		return err
+	}
+	updates := b.upsertUpdates()
+	switch upsertOf(d) {
+	case UpsertOnConflict:
+		if len(updates) > 0 && len(b.upsert.cols) == 0 {
+			return ErrNoConflictTarget
+		}
+		if err := b.insertValuesWriteTo(w); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(w, " ON CONFLICT"); err != nil {
+			return err
+		}
+		if len(b.upsert.cols) > 0 {
+			if _, err := fmt.Fprint(w, " ("); err != nil {
+				return err
+			}
+			for i, col := range b.upsert.cols {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprint(w, quoteIdent(w, col))
+			}
+			fmt.Fprint(w, ")")
+		}
+		if len(updates) == 0 {
+			_, err := fmt.Fprint(w, " DO NOTHING")
+			return err
+		}
+		if _, err := fmt.Fprint(w, " DO UPDATE SET "); err != nil {
+			return err
+		}
+		uw := withDialect(w, d)
+		uw.excluded = func(col string) string {
+			return quoteIdent(uw, "excluded."+col)
+		}
+		return writeUpdates(uw, updates)
+	case UpsertOnDuplicateKey:
+		if err := b.insertValuesWriteTo(w); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(w, " ON DUPLICATE KEY UPDATE "); err != nil {
+			return err
+		}
+		if len(updates) == 0 {
+			// there is no DO NOTHING, so the first column is set to itself
+			col := quoteIdent(w, b.insertCols[0])
+			_, err := fmt.Fprintf(w, "%s=%s", col, col)
+			return err
+		}
+		uw := withDialect(w, d)
+		uw.excluded = func(col string) string {
+			return fmt.Sprintf("VALUES(%s)", quoteIdent(uw, col))
+		}
+		return writeUpdates(uw, updates)
+	case UpsertMerge, UpsertMergeTSQL:
+		return b.mergeWriteTo(w, d, updates)
+	}
+	return ErrNotSupportDialectType
+}
+
+// mergeWriteTo writes an upsert as MERGE statement, the inserted values are selected as
+// excluded source which is matched to the target table by the conflict target columns
+func (b *Builder) mergeWriteTo(w Writer, d SQLDialect, updates []UpdateCond) error {
+	if len(b.upsert.cols) == 0 {
+		return ErrNoConflictTarget
+	}
+	tsql := upsertOf(d) == UpsertMergeTSQL
+	if _, err := fmt.Fprintf(w, "MERGE INTO %s ", quoteIdent(w, b.into)); err != nil {
+		return err
+	}
+	if tsql {
+		fmt.Fprint(w, "WITH (HOLDLOCK) ")
+	}
+	fmt.Fprintf(w, "%s USING (", quoteIdent(w, "target"))
+	for r, row := range b.insertRowValues() {
+		if r > 0 {
+			fmt.Fprint(w, " UNION ALL ")
+		}
+		fmt.Fprint(w, "SELECT ")
+		for i, col := range b.insertCols {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			if err := writeInsertValue(w, row[i]); err != nil {
+				return err
+			}
+			fmt.Fprint(w, " ", quoteIdent(w, col))
+		}
+		if d.Supports(FeatureDualTable) {
+			fmt.Fprint(w, " FROM DUAL")
+		}
+	}
+	fmt.Fprintf(w, ") %s ON (", quoteIdent(w, "excluded"))
+	for i, col := range b.upsert.cols {
+		if i > 0 {
+			fmt.Fprint(w, " AND ")
+		}
+		fmt.Fprintf(w, "%s=%s", quoteIdent(w, "target."+col), quoteIdent(w, "excluded."+col))
+	}
+	fmt.Fprint(w, ")")
+	if len(updates) > 0 {
+		if _, err := fmt.Fprint(w, " WHEN MATCHED THEN UPDATE SET "); err != nil {
+			return err
+		}
+		uw := withDialect(w, d)
+		uw.excluded = func(col string) string {
+			return quoteIdent(uw, "excluded."+col)
+		}
+		if err := writeUpdates(uw, updates); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(w, " WHEN NOT MATCHED THEN INSERT (")
+	for i, col := range b.insertCols {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprint(w, quoteIdent(w, col))
+	}
+	fmt.Fprint(w, ") VALUES (")
+	for i, col := range b.insertCols {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprint(w, quoteIdent(w, "excluded."+col))
+	}
+	fmt.Fprint(w, ")")
+	if err := b.outputWriteTo(w, "INSERTED"); err != nil {
+		return err
+	}
+	if tsql {
+		fmt.Fprint(w, ";")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderUpsert_OnConflict(t *testing.T) {
+	sql, args, err := Postgres().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").OnConflict("id").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id,name) Values ($1,$2) ON CONFLICT (id) DO NOTHING", sql)
+	assert.EqualValues(t, []interface{}{1, "cat"}, args)
+
+	sql, args, err = SQLite().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").DoNothing().ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id,name) Values (?,?) ON CONFLICT DO NOTHING", sql)
+	assert.EqualValues(t, []interface{}{1, "cat"}, args)
+
+	sql, args, err = Postgres().Insert(Eq{"id": 1, "name": "cat", "visits": 1}).Into("table1").
+		OnConflict("id").DoUpdate(Eq{"name": Excluded("name"), "visits": Expr("table1.visits+?", 1)}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id,name,visits) Values ($1,$2,$3) ON CONFLICT (id) DO UPDATE SET name=excluded.name,visits=(table1.visits+$4)", sql)
+	assert.EqualValues(t, []interface{}{1, "cat", 1, 1}, args)
+
+	sql, err = Postgres().QuoteIdents().Insert(Eq{"id": 1, "user": "cat", "order": 2}).Into("table1").
+		OnConflict("id", "order").DoUpdate().ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `INSERT INTO "table1" ("id","order","user") Values (1,2,'cat') ON CONFLICT ("id","order") DO UPDATE SET "user"="excluded"."user"`, sql)
+
+	_, _, err = Postgres().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").DoUpdate().ToSQL()
+	assert.EqualValues(t, ErrNoConflictTarget, err)
+}
+
+func TestBuilderUpsert_OnDuplicateKey(t *testing.T) {
+	sql, args, err := MySQL().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").OnConflict("id").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id,name) Values (?,?) ON DUPLICATE KEY UPDATE id=id", sql)
+	assert.EqualValues(t, []interface{}{1, "cat"}, args)
+
+	sql, args, err = MySQL().Insert(Eq{"id": 1, "name": "cat", "visits": 1}).Into("table1").
+		OnConflict("id").DoUpdate(Eq{"name": Excluded("name"), "visits": Incr(1)}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (id,name,visits) Values (?,?,?) ON DUPLICATE KEY UPDATE name=VALUES(name),visits=visits+?", sql)
+	assert.EqualValues(t, []interface{}{1, "cat", 1, 1}, args)
+
+	sql, err = MySQL().QuoteIdents().Insert(Eq{"id": 1, "order": 2}).Into("table1").OnConflict("id").DoUpdate().ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO `table1` (`id`,`order`) Values (1,2) ON DUPLICATE KEY UPDATE `order`=VALUES(`order`)", sql)
+}
+
+func TestBuilderUpsert_Merge(t *testing.T) {
+	sql, args, err := MsSQL().Insert(Eq{"id": 1, "name": "cat", "note": nil}).Into("table1").
+		OnConflict("id").DoUpdate().ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "MERGE INTO table1 WITH (HOLDLOCK) target USING (SELECT @p1 id,@p2 name,null note) excluded ON (target.id=excluded.id) WHEN MATCHED THEN UPDATE SET name=excluded.name,note=excluded.note WHEN NOT MATCHED THEN INSERT (id,name,note) VALUES (excluded.id,excluded.name,excluded.note);", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("p1", 1), sql2.Named("p2", "cat")}, args)
+
+	sql, args, err = Oracle().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").OnConflict("id").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "MERGE INTO table1 target USING (SELECT :p1 id,:p2 name FROM DUAL) excluded ON (target.id=excluded.id) WHEN NOT MATCHED THEN INSERT (id,name) VALUES (excluded.id,excluded.name)", sql)
+	assert.EqualValues(t, []interface{}{sql2.Named("p1", 1), sql2.Named("p2", "cat")}, args)
+
+	sql, err = Oracle().QuoteIdents().Insert(Eq{"id": 1, "name": "cat"}).Into("table1").
+		OnConflict("id").DoUpdate(Eq{"name": Expr("UPPER(?)", "dog")}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `MERGE INTO "table1" "target" USING (SELECT 1 "id",'cat' "name" FROM DUAL) "excluded" ON ("target"."id"="excluded"."id") WHEN MATCHED THEN UPDATE SET "name"=(UPPER('dog')) WHEN NOT MATCHED THEN INSERT ("id","name") VALUES ("excluded"."id","excluded"."name")`, sql)
+
+	_, _, err = MsSQL().Insert(Eq{"id": 1}).Into("table1").DoNothing().ToSQL()
+	assert.EqualValues(t, ErrNoConflictTarget, err)
+}
+
+func TestBuilderUpsert_Errors(t *testing.T) {
+	_, _, err := Insert(Eq{"id": 1}).Into("table1").OnConflict("id").ToSQL()
+	assert.EqualValues(t, ErrDialectNotSetUp, err)
+
+	_, _, err = Postgres().Insert("a").Into("table1").Select("a").From("table2").OnConflict("a").ToSQL()
+	assert.EqualValues(t, ErrUnsupportedUpsert, err)
+
+	_, _, err = Postgres().Update(Eq{"name": Excluded("name")}).From("table1").ToSQL()
+	assert.EqualValues(t, ErrUnexpectedExcluded, err)
+}
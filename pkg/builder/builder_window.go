@@ -0,0 +1,350 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameBound is the start or the end of a window frame
+type FrameBound string
+
+// frame bounds which do not depend on an offset
+const (
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+	CurrentRow         FrameBound = "CURRENT ROW"
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding bounds a window frame n rows or values before the current row
+func Preceding(n int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d PRECEDING", n))
+}
+
+// Following bounds a window frame n rows or values after the current row
+func Following(n int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d FOLLOWING", n))
+}
+
+// Window describes the window of a window function, i.e. the contents of its OVER clause
+// or of a named window of the WINDOW clause
+type Window struct {
+	base        string
+	partitionBy []string
+	orderBy     []string
+	frame       string
+	start, end  FrameBound
+}
+
+// NewWindow creates a window. It refines the named window base if one is given.
+func NewWindow(base ...string) *Window {
+	w := &Window{}
+	if len(base) > 0 {
+		w.base = base[0]
+	}
+	return w
+}
+
+// PartitionBy adds columns or expressions rows are partitioned by
+func (w *Window) PartitionBy(cols ...string) *Window {
+	w.partitionBy = append(w.partitionBy, cols...)
+	return w
+}
+
+// OrderBy adds columns or expressions the rows of a partition are ordered by, e.g. "created DESC"
+func (w *Window) OrderBy(cols ...string) *Window {
+	w.orderBy = append(w.orderBy, cols...)
+	return w
+}
+
+// Rows sets a frame of rows from start to end. Without end the frame ends with the current row.
+func (w *Window) Rows(start FrameBound, end ...FrameBound) *Window {
+	return w.setFrame("ROWS", start, end)
+}
+
+// Range sets a frame of the rows whose order by values are between start and end.
+// Without end the frame ends with the peers of the current row.
+func (w *Window) Range(start FrameBound, end ...FrameBound) *Window {
+	return w.setFrame("RANGE", start, end)
+}
+
+func (w *Window) setFrame(frame string, start FrameBound, end []FrameBound) *Window {
+	w.frame, w.start, w.end = frame, start, ""
+	if len(end) > 0 {
+		w.end = end[0]
+	}
+	return w
+}
+
+// inline replaces the named window w refines by its definition. Like SQL, a window can only add the
+// ordering to a base window without one and a frame to a base window without one, but never partition
+// it. seen holds the names of the windows being inlined, which must not be based on each other.
+func (w *Window) inline(windows []namedWindow, seen ...string) (*Window, error) {
+	if w.base == "" {
+		return w, nil
+	}
+	for _, name := range seen {
+		if name == w.base {
+			return nil, ErrRecursiveWindow
+		}
+	}
+	var base *Window
+	for _, nw := range windows {
+		if nw.name == w.base {
+			var err error
+			if base, err = nw.window.inline(windows, append(seen, nw.name)...); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if base == nil {
+		return w, nil
+	}
+	if len(w.partitionBy) == 0 && len(w.orderBy) == 0 && w.frame == "" {
+		return base, nil
+	}
+	if len(w.partitionBy) > 0 || (len(w.orderBy) > 0 && len(base.orderBy) > 0) || base.frame != "" {
+		return nil, ErrInvalidWindowRefinement
+	}
+	res := &Window{
+		partitionBy: base.partitionBy,
+		orderBy:     base.orderBy,
+		frame:       w.frame,
+		start:       w.start,
+		end:         w.end,
+	}
+	if len(res.orderBy) == 0 {
+		res.orderBy = w.orderBy
+	}
+	return res, nil
+}
+
+// writeTo writes the window specification without the surrounding parentheses
+func (w *Window) writeTo(wr Writer) error {
+	var parts []string
+	if w.base != "" {
+		parts = append(parts, quoteIdent(wr, w.base))
+	}
+	if len(w.partitionBy) > 0 {
+		cols := make([]string, len(w.partitionBy))
+		for i, col := range w.partitionBy {
+			cols[i] = quoteIdent(wr, col)
+		}
+		parts = append(parts, "PARTITION BY "+strings.Join(cols, ","))
+	}
+	if len(w.orderBy) > 0 {
+		cols := make([]string, len(w.orderBy))
+		for i, col := range w.orderBy {
+			cols[i] = quoteOrder(wr, col)
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(cols, ","))
+	}
+	if w.frame != "" {
+		if w.end == "" {
+			parts = append(parts, fmt.Sprintf("%s %s", w.frame, w.start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s BETWEEN %s AND %s", w.frame, w.start, w.end))
+		}
+	}
+	_, err := fmt.Fprint(wr, strings.Join(parts, " "))
+	return err
+}
+
+// WindowExpr is a window function call like ROW_NUMBER() OVER (PARTITION BY a ORDER BY b).
+// It can be selected with Builder.SelectExpr and ordered by with Builder.OrderByExpr.
+type WindowExpr struct {
+	fn     string
+	args   []interface{}
+	window *Window
+	alias  string
+}
+
+var _ Cond = &WindowExpr{}
+
+// WindowFunc creates a call of the window or aggregate function fn, e.g. "SUM(amount)" or "LAG(price,?)".
+// args are bound to the placeholders of fn.
+func WindowFunc(fn string, args ...interface{}) *WindowExpr {
+	return &WindowExpr{fn: fn, args: args}
+}
+
+// Over sets the window of the function. A window which only names a window of the WINDOW clause,
+// e.g. NewWindow("w"), is referenced by its name.
+func (e *WindowExpr) Over(window *Window) *WindowExpr {
+	e.window = window
+	return e
+}
+
+// PartitionBy adds columns or expressions rows are partitioned by
+func (e *WindowExpr) PartitionBy(cols ...string) *WindowExpr {
+	e.ensureWindow().PartitionBy(cols...)
+	return e
+}
+
+// OrderBy adds columns or expressions the rows of a partition are ordered by
+func (e *WindowExpr) OrderBy(cols ...string) *WindowExpr {
+	e.ensureWindow().OrderBy(cols...)
+	return e
+}
+
+// Rows sets a frame of rows, see Window.Rows
+func (e *WindowExpr) Rows(start FrameBound, end ...FrameBound) *WindowExpr {
+	e.ensureWindow().Rows(start, end...)
+	return e
+}
+
+// Range sets a frame of values, see Window.Range
+func (e *WindowExpr) Range(start FrameBound, end ...FrameBound) *WindowExpr {
+	e.ensureWindow().Range(start, end...)
+	return e
+}
+
+// As sets the alias the function is selected as. Ordering by the function leaves it out.
+func (e *WindowExpr) As(alias string) *WindowExpr {
+	e.alias = alias
+	return e
+}
+
+func (e *WindowExpr) ensureWindow() *Window {
+	if e.window == nil {
+		e.window = NewWindow()
+	}
+	return e.window
+}
+
+// WriteTo implements Cond
+func (e *WindowExpr) WriteTo(w Writer) error {
+	if _, err := fmt.Fprint(w, e.fn, " OVER "); err != nil {
+		return err
+	}
+	w.Append(e.args...)
+	window := e.window
+	if window == nil {
+		window = NewWindow()
+	}
+	if dw, ok := w.(*dialectWriter); ok && len(dw.windows) > 0 {
+		var err error
+		if window, err = window.inline(dw.windows); err != nil {
+			return err
+		}
+	}
+	if window.base != "" && len(window.partitionBy) == 0 && len(window.orderBy) == 0 && window.frame == "" {
+		if _, err := fmt.Fprint(w, quoteIdent(w, window.base)); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprint(w, "("); err != nil {
+			return err
+		}
+		if err := window.writeTo(w); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(w, ")"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// And implements Cond
+func (e *WindowExpr) And(conds ...Cond) Cond {
+	return And(e, And(conds...))
+}
+
+// Or implements Cond
+func (e *WindowExpr) Or(conds ...Cond) Cond {
+	return Or(e, Or(conds...))
+}
+
+// IsValid implements Cond
+func (e *WindowExpr) IsValid() bool {
+	return len(e.fn) > 0
+}
+
+// selectExprWriteTo writes an expression of the select list, along with its alias if it has one
+func selectExprWriteTo(w Writer, e Cond) error {
+	if err := e.WriteTo(w); err != nil {
+		return err
+	}
+	if we, ok := e.(*WindowExpr); ok && we.alias != "" {
+		if _, err := fmt.Fprint(w, " AS ", quoteIdent(w, we.alias)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type namedWindow struct {
+	name   string
+	window *Window
+}
+
+// Window adds the named window name to the WINDOW clause of a select. Dialects without a WINDOW
+// clause get the definition inlined into the OVER clauses referencing it.
+func (b *Builder) Window(name string, window *Window) *Builder {
+	b.windows = append(b.windows, namedWindow{name, window})
+	return b
+}
+
+// SelectExpr adds expressions, e.g. window functions, to the columns of a select
+func (b *Builder) SelectExpr(exprs ...Cond) *Builder {
+	b.selectExprs = append(b.selectExprs, exprs...)
+	if b.optype == condType {
+		b.optype = selectType
+	}
+	return b
+}
+
+// OrderByExpr adds expressions, e.g. window functions or columns ordered by Asc and Desc,
+// the rows of a select are ordered by
+func (b *Builder) OrderByExpr(exprs ...Cond) *Builder {
+	b.orderBy = append(b.orderBy, exprs...)
+	return b
+}
+
+// windowsWriteTo writes the WINDOW clause or, if the dialect has none, sets w up to inline the
+// named windows
+func (b *Builder) windowsWriteTo(w Writer) error {
+	if len(b.windows) == 0 || !b.supports(FeatureWindowClause) {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, " WINDOW "); err != nil {
+		return err
+	}
+	for i, nw := range b.windows {
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, quoteIdent(w, nw.name), " AS ("); err != nil {
+			return err
+		}
+		if err := nw.window.writeTo(w); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(w, ")"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
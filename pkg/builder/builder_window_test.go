@@ -0,0 +1,106 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_WindowFunc(t *testing.T) {
+	sql, args, err := Postgres().Select("id", "dept").
+		SelectExpr(WindowFunc("ROW_NUMBER()").PartitionBy("dept").OrderBy("salary DESC", "id").As("rn")).
+		SelectExpr(WindowFunc("LAG(salary,?,?)", 1, 0).OrderBy("id").As("prev")).
+		From("employee").Where(Eq{"active": true}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,dept,ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC,id) AS rn,LAG(salary,$1,$2) OVER (ORDER BY id) AS prev FROM employee WHERE active=$3", sql)
+	assert.EqualValues(t, []interface{}{1, 0, true}, args)
+
+	sql, args, err = Select().SelectExpr(WindowFunc("COUNT(*)")).From("employee").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT COUNT(*) OVER () FROM employee", sql)
+	assert.Empty(t, args)
+
+	sql, args, err = MySQL().Select("id").From("employee").
+		OrderByExpr(WindowFunc("SUM(salary)").PartitionBy("dept").Range(UnboundedPreceding)).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM employee ORDER BY SUM(salary) OVER (PARTITION BY dept RANGE UNBOUNDED PRECEDING)", sql)
+	assert.Empty(t, args)
+
+	// the alias belongs to the select list only
+	rn := WindowFunc("ROW_NUMBER()").OrderBy("b").As("rn")
+	sql, err = Postgres().Select("a").SelectExpr(rn).From("t").OrderByExpr(rn).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a,ROW_NUMBER() OVER (ORDER BY b) AS rn FROM t ORDER BY ROW_NUMBER() OVER (ORDER BY b)", sql)
+
+	sql, err = Postgres().QuoteIdents().SelectExpr(WindowFunc("SUM(amount)").PartitionBy("user").
+		OrderBy("e.order DESC NULLS LAST").Rows(Preceding(2), Following(1)).As("sum")).
+		From("entry", "e").OrderBy("id").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT SUM(amount) OVER (PARTITION BY "user" ORDER BY "e"."order" DESC NULLS LAST ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING) AS "sum" FROM "entry" "e" ORDER BY id`, sql)
+}
+
+func TestBuilder_Window(t *testing.T) {
+	query := func(dialect string) *Builder {
+		return Dialect(dialect).Select("id").
+			SelectExpr(WindowFunc("RANK()").Over(NewWindow("w")).As("r")).
+			SelectExpr(WindowFunc("SUM(salary)").Over(NewWindow("w").Rows(UnboundedPreceding, CurrentRow)).As("s")).
+			From("employee").Where(Gt{"salary": 100}).
+			Window("w", NewWindow().PartitionBy("dept").OrderBy("salary DESC")).OrderBy("id")
+	}
+
+	sql, args, err := query(POSTGRES).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,RANK() OVER w AS r,SUM(salary) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS s FROM employee WHERE salary>$1 WINDOW w AS (PARTITION BY dept ORDER BY salary DESC) ORDER BY id", sql)
+	assert.EqualValues(t, []interface{}{100}, args)
+
+	sql, args, err = query(MSSQL).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,RANK() OVER (PARTITION BY dept ORDER BY salary DESC) AS r,SUM(salary) OVER (PARTITION BY dept ORDER BY salary DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS s FROM employee WHERE salary>@p1 ORDER BY id", sql)
+	assert.Len(t, args, 1)
+
+	sql, err = query(MSSQL).Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 id,r,s FROM (SELECT id,RANK() OVER (PARTITION BY dept ORDER BY salary DESC) AS r,SUM(salary) OVER (PARTITION BY dept ORDER BY salary DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS s,ROW_NUMBER() OVER (ORDER BY id) AS RN FROM employee WHERE salary>100) at WHERE at.RN>10 ORDER BY at.RN ASC", sql)
+
+	// inlined windows are refined the way SQL allows only
+	sql, err = MsSQL().Select("id").From("employee").Window("p", NewWindow().PartitionBy("dept")).
+		Window("o", NewWindow("p").OrderBy("salary")).
+		SelectExpr(WindowFunc("SUM(salary)").Over(NewWindow("o").Rows(UnboundedPreceding))).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,SUM(salary) OVER (PARTITION BY dept ORDER BY salary ROWS UNBOUNDED PRECEDING) FROM employee", sql)
+	for _, refined := range []*Window{
+		NewWindow("w").PartitionBy("team"),
+		NewWindow("w").OrderBy("id"),
+		NewWindow("f").Rows(CurrentRow),
+	} {
+		_, _, err = MsSQL().Select("id").From("employee").
+			Window("w", NewWindow().PartitionBy("dept").OrderBy("salary")).
+			Window("f", NewWindow().Rows(UnboundedPreceding)).
+			SelectExpr(WindowFunc("RANK()").Over(refined)).ToSQL()
+		assert.EqualValues(t, ErrInvalidWindowRefinement, err)
+	}
+	_, _, err = MsSQL().Select("id").From("employee").
+		Window("a", NewWindow("b")).Window("b", NewWindow("a").OrderBy("id")).
+		SelectExpr(WindowFunc("RANK()").Over(NewWindow("a"))).ToSQL()
+	assert.EqualValues(t, ErrRecursiveWindow, err)
+}
@@ -0,0 +1,140 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+)
+
+type cte struct {
+	name      string
+	cols      []string
+	query     *Builder
+	recursive bool
+}
+
+// With creates a Builder with a common table expression
+func With(name string, query *Builder, cols ...string) *Builder {
+	builder := &Builder{cond: NewCond()}
+	return builder.With(name, query, cols...)
+}
+
+// WithRecursive creates a Builder with a recursive common table expression
+func WithRecursive(name string, query *Builder, cols ...string) *Builder {
+	builder := &Builder{cond: NewCond()}
+	return builder.WithRecursive(name, query, cols...)
+}
+
+// With adds a common table expression named name, which can be referenced by the statement
+// and the common table expressions added after it
+func (b *Builder) With(name string, query *Builder, cols ...string) *Builder {
+	b.ctes = append(b.ctes, cte{name: name, cols: cols, query: query})
+	return b
+}
+
+// WithRecursive adds a recursive common table expression named name. Its query is usually
+// a UNION ALL of a select of the initial rows and a select joining name.
+func (b *Builder) WithRecursive(name string, query *Builder, cols ...string) *Builder {
+	b.ctes = append(b.ctes, cte{name: name, cols: cols, query: query, recursive: true})
+	return b
+}
+
+// supports reports whether the dialect of the builder supports feature, assuming it does if the dialect is unknown
+func (b *Builder) supports(feature Feature) bool {
+	if d := GetDialect(b.dialect); d != nil {
+		return d.Supports(feature)
+	}
+	return true
+}
+
+func (b *Builder) withWriteTo(w Writer) error {
+	if len(b.ctes) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, "WITH "); err != nil {
+		return err
+	}
+	for _, c := range b.ctes {
+		if c.recursive && b.supports(FeatureWithRecursive) {
+			if _, err := fmt.Fprint(w, "RECURSIVE "); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for i, c := range b.ctes {
+		if c.query == nil {
+			return ErrUnexpectedSubQuery
+		}
+		if c.query.dialect != "" && b.dialect != "" && c.query.dialect != b.dialect {
+			return ErrInconsistentDialect
+		}
+		// dialect of the query will inherit from the main one (if not set up)
+		query := c.query.inheritDialect(b.dialect)
+		if i > 0 {
+			if _, err := fmt.Fprint(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, quoteIdent(w, c.name)); err != nil {
+			return err
+		}
+		if len(c.cols) > 0 {
+			if _, err := fmt.Fprint(w, "("); err != nil {
+				return err
+			}
+			for j, col := range c.cols {
+				if j > 0 {
+					if _, err := fmt.Fprint(w, ","); err != nil {
+						return err
+					}
+				}
+				if _, err := fmt.Fprint(w, quoteIdent(w, col)); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprint(w, ")"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, " AS ("); err != nil {
+			return err
+		}
+		switch query.optype {
+		case selectType:
+			if err := query.WriteTo(w); err != nil {
+				return err
+			}
+		case setOpType:
+			// the members of a recursive query must not be put into parentheses
+			if err := query.setOpMembersWriteTo(w, false); err != nil {
+				return err
+			}
+		default:
+			return ErrUnexpectedSubQuery
+		}
+		if _, err := fmt.Fprint(w, ")"); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, " ")
+	return err
+}
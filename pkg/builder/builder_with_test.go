@@ -0,0 +1,110 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_With(t *testing.T) {
+	sql, args, err := Postgres().With("active", Select("id").From("user").Where(Eq{"state": "active"})).
+		With("recent", Select("user_id").From("order").Where(Gt{"created": 10})).
+		Select("a.id").From("active a").InnerJoin("recent r", "r.user_id = a.id").Where(Neq{"a.id": 3}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH active AS (SELECT id FROM user WHERE state=$1),recent AS (SELECT user_id FROM order WHERE created>$2) SELECT a.id FROM active a INNER JOIN recent r ON r.user_id = a.id WHERE a.id<>$3", sql)
+	assert.EqualValues(t, []interface{}{"active", 10, 3}, args)
+
+	sql, args, err = With("t", Select("a").From("table1").Where(Eq{"b": 1}), "x").Select("x").From("t").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH t(x) AS (SELECT a FROM table1 WHERE b=?) SELECT x FROM t", sql)
+	assert.EqualValues(t, []interface{}{1}, args)
+
+	sql, err = MySQL().QuoteIdents().With("order", Select("id").From("user")).Select("id").From("order").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH `order` AS (SELECT `id` FROM `user`) SELECT `id` FROM `order`", sql)
+}
+
+func TestBuilder_WithRecursive(t *testing.T) {
+	tree := Select("id", "parent_id").From("category").Where(Eq{"id": 1}).
+		Union("ALL", Select("c.id", "c.parent_id").From("category c").InnerJoin("tree t", "c.parent_id = t.id").Where(Neq{"c.state": "deleted"}))
+
+	sql, args, err := Postgres().WithRecursive("tree", tree).Select("id").From("tree").Where(Neq{"id": 2}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH RECURSIVE tree AS (SELECT id,parent_id FROM category WHERE id=$1 UNION ALL SELECT c.id,c.parent_id FROM category c INNER JOIN tree t ON c.parent_id = t.id WHERE c.state<>$2) SELECT id FROM tree WHERE id<>$3", sql)
+	assert.EqualValues(t, []interface{}{1, "deleted", 2}, args)
+
+	tree = Select("id", "parent_id").From("category").Where(Eq{"id": 1}).
+		Union("ALL", Select("c.id", "c.parent_id").From("category c").InnerJoin("tree t", "c.parent_id = t.id"))
+	sql, err = MsSQL().WithRecursive("tree", tree, "id", "parent_id").Select("id").From("tree").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH tree(id,parent_id) AS (SELECT id,parent_id FROM category WHERE id=1 UNION ALL SELECT c.id,c.parent_id FROM category c INNER JOIN tree t ON c.parent_id = t.id) SELECT id FROM tree", sql)
+}
+
+func TestBuilder_WithStatements(t *testing.T) {
+	ids := func() *Builder { return Select("id").From("user").Where(Eq{"state": "gone"}) }
+
+	sql, args, err := Postgres().With("gone", ids()).Delete(Expr("user_id IN (SELECT id FROM gone)")).From("order").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH gone AS (SELECT id FROM user WHERE state=$1) DELETE FROM order WHERE (user_id IN (SELECT id FROM gone))", sql)
+	assert.EqualValues(t, []interface{}{"gone"}, args)
+
+	sql, args, err = MySQL().With("gone", ids()).Update(Eq{"state": "closed"}).From("order").Where(Expr("user_id IN (SELECT id FROM gone)")).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH gone AS (SELECT id FROM user WHERE state=?) UPDATE order SET state=? WHERE user_id IN (SELECT id FROM gone)", sql)
+	assert.EqualValues(t, []interface{}{"gone", "closed"}, args)
+
+	sql, args, err = Postgres().With("gone", ids()).Insert("id").Into("archive").Select("id").From("gone").Where(Gt{"id": 5}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH gone AS (SELECT id FROM user WHERE state=$1) INSERT INTO archive (id) SELECT id FROM gone WHERE id>$2", sql)
+	assert.EqualValues(t, []interface{}{"gone", 5}, args)
+
+	sql, args, err = Oracle().With("gone", ids()).Insert("id").Into("archive").Select("id").From("gone").Where(Gt{"id": 5}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO archive (id) WITH gone AS (SELECT id FROM user WHERE state=:p1) SELECT id FROM gone WHERE id>:p2", sql)
+	assert.Len(t, args, 2)
+
+	sql, args, err = Postgres().With("n", Select("max(id) AS id").From("user")).Insert(Eq{"id": Expr("(SELECT id FROM n)+?", 1), "state": "new"}).Into("user").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH n AS (SELECT max(id) AS id FROM user) INSERT INTO user (id,state) Values (((SELECT id FROM n)+$1),$2)", sql)
+	assert.EqualValues(t, []interface{}{1, "new"}, args)
+
+	_, _, err = MySQL().With("gone", ids()).Insert(Eq{"id": 1}).Into("archive").ToSQL()
+	assert.EqualValues(t, ErrNotSupportCTE, err)
+	_, _, err = Oracle().With("gone", ids()).Delete(Eq{"id": 1}).From("archive").ToSQL()
+	assert.EqualValues(t, ErrNotSupportCTE, err)
+}
+
+func TestBuilder_WithLimitAndUnion(t *testing.T) {
+	sql, err := MsSQL().With("t", Select("a").From("table1")).Select("a").From("t").OrderBy("a").Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH t AS (SELECT a FROM table1) SELECT TOP 5 a FROM (SELECT a,ROW_NUMBER() OVER (ORDER BY a) AS RN FROM t) at WHERE at.RN>10 ORDER BY at.RN ASC", sql)
+
+	sql, err = MySQL().With("t", Select("a").From("table1")).Select("a").From("t").Limit(5).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH t AS (SELECT a FROM table1) SELECT a FROM t LIMIT 5", sql)
+
+	sql, err = Postgres().With("t", Select("a").From("table1")).Select("a").From("t").Where(Eq{"a": 1}).
+		Union("ALL", Select("a").From("t").Where(Eq{"a": 2})).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH t AS (SELECT a FROM table1) (SELECT a FROM t WHERE a=1) UNION ALL (SELECT a FROM t WHERE a=2)", sql)
+}
@@ -34,7 +34,7 @@ func WriteMap(w Writer, data map[string]interface{}, op string) error {
 		v := data[k]
 		switch v.(type) {
 		case expr:
-			if _, err := fmt.Fprintf(w, "%s%s(", k, op); err != nil {
+			if _, err := fmt.Fprintf(w, "%s%s(", quoteIdent(w, k), op); err != nil {
 				return err
 			}
 			if err := v.(expr).WriteTo(w); err != nil {
@@ -44,7 +44,7 @@ func WriteMap(w Writer, data map[string]interface{}, op string) error {
 				return err
 			}
 		case *Builder:
-			if _, err := fmt.Fprintf(w, "%s%s(", k, op); err != nil {
+			if _, err := fmt.Fprintf(w, "%s%s(", quoteIdent(w, k), op); err != nil {
 				return err
 			}
 			if err := v.(*Builder).WriteTo(w); err != nil {
@@ -54,7 +54,7 @@ func WriteMap(w Writer, data map[string]interface{}, op string) error {
 				return err
 			}
 		default:
-			if _, err := fmt.Fprintf(w, "%s%s?", k, op); err != nil {
+			if _, err := fmt.Fprintf(w, "%s%s?", quoteIdent(w, k), op); err != nil {
 				return err
 			}
 			args = append(args, v)
@@ -47,7 +47,7 @@ func (eq Eq) OpWriteTo(op string, w Writer) error {
 				return err
 			}
 		case expr:
-			if _, err := fmt.Fprintf(w, "%s=(", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=(", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			if err := v.(expr).WriteTo(w); err != nil {
@@ -57,7 +57,7 @@ func (eq Eq) OpWriteTo(op string, w Writer) error {
 				return err
 			}
 		case *Builder:
-			if _, err := fmt.Fprintf(w, "%s=(", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=(", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			if err := v.(*Builder).WriteTo(w); err != nil {
@@ -67,21 +67,29 @@ func (eq Eq) OpWriteTo(op string, w Writer) error {
 				return err
 			}
 		case Incr:
-			if _, err := fmt.Fprintf(w, "%s=%s+?", k, k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=%s+?", quoteIdent(w, k), quoteIdent(w, k)); err != nil {
 				return err
 			}
 			w.Append(int(v.(Incr)))
 		case Decr:
-			if _, err := fmt.Fprintf(w, "%s=%s-?", k, k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=%s-?", quoteIdent(w, k), quoteIdent(w, k)); err != nil {
 				return err
 			}
 			w.Append(int(v.(Decr)))
+		case Excluded:
+			ref, err := excludedRef(w, string(v.(Excluded)))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s", quoteIdent(w, k), ref); err != nil {
+				return err
+			}
 		case nil:
-			if _, err := fmt.Fprintf(w, "%s=null", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=null", quoteIdent(w, k)); err != nil {
 				return err
 			}
 		default:
-			if _, err := fmt.Fprintf(w, "%s=?", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s=?", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			w.Append(v)
@@ -42,7 +42,7 @@ func (neq Neq) WriteTo(w Writer) error {
 				return err
 			}
 		case expr:
-			if _, err := fmt.Fprintf(w, "%s<>(", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s<>(", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			if err := v.(expr).WriteTo(w); err != nil {
@@ -52,7 +52,7 @@ func (neq Neq) WriteTo(w Writer) error {
 				return err
 			}
 		case *Builder:
-			if _, err := fmt.Fprintf(w, "%s<>(", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s<>(", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			if err := v.(*Builder).WriteTo(w); err != nil {
@@ -62,7 +62,7 @@ func (neq Neq) WriteTo(w Writer) error {
 				return err
 			}
 		default:
-			if _, err := fmt.Fprintf(w, "%s<>?", k); err != nil {
+			if _, err := fmt.Fprintf(w, "%s<>?", quoteIdent(w, k)); err != nil {
 				return err
 			}
 			args = append(args, v)
@@ -49,7 +49,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -61,7 +61,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -73,7 +73,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -85,7 +85,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -97,7 +97,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -109,7 +109,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -121,7 +121,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -133,7 +133,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -145,7 +145,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -157,7 +157,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -169,7 +169,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		for _, val := range vals {
@@ -181,13 +181,13 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			return condNotIn.handleBlank(w)
 		}
 		questionMark := strings.Repeat("?,", len(vals))
-		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 			return err
 		}
 		w.Append(vals...)
 	case expr:
 		val := condNotIn.vals[0].(expr)
-		if _, err := fmt.Fprintf(w, "%s NOT IN (", condNotIn.col); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (", quoteIdent(w, condNotIn.col)); err != nil {
 			return err
 		}
 		if err := val.WriteTo(w); err != nil {
@@ -198,7 +198,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 		}
 	case *Builder:
 		val := condNotIn.vals[0].(*Builder)
-		if _, err := fmt.Fprintf(w, "%s NOT IN (", condNotIn.col); err != nil {
+		if _, err := fmt.Fprintf(w, "%s NOT IN (", quoteIdent(w, condNotIn.col)); err != nil {
 			return err
 		}
 		if err := val.WriteTo(w); err != nil {
@@ -215,7 +215,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 				return condNotIn.handleBlank(w)
 			}
 			questionMark := strings.Repeat("?,", l)
-			if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+			if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 				return err
 			}
 			for i := 0; i < l; i++ {
@@ -223,7 +223,7 @@ func (condNotIn condNotIn) WriteTo(w Writer) error {
 			}
 		} else {
 			questionMark := strings.Repeat("?,", len(condNotIn.vals))
-			if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", condNotIn.col, questionMark[:len(questionMark)-1]); err != nil {
+			if _, err := fmt.Fprintf(w, "%s NOT IN (%s)", quoteIdent(w, condNotIn.col), questionMark[:len(questionMark)-1]); err != nil {
 				return err
 			}
 			w.Append(condNotIn.vals...)
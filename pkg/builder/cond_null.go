@@ -29,7 +29,7 @@ var _ Cond = IsNull{""}
 
 // WriteTo write SQL to Writer
 func (isNull IsNull) WriteTo(w Writer) error {
-	_, err := fmt.Fprintf(w, "%s IS NULL", isNull[0])
+	_, err := fmt.Fprintf(w, "%s IS NULL", quoteIdent(w, isNull[0]))
 	return err
 }
 
@@ -55,7 +55,7 @@ var _ Cond = NotNull{""}
 
 // WriteTo write SQL to Writer
 func (notNull NotNull) WriteTo(w Writer) error {
-	_, err := fmt.Fprintf(w, "%s IS NOT NULL", notNull[0])
+	_, err := fmt.Fprintf(w, "%s IS NOT NULL", quoteIdent(w, notNull[0]))
 	return err
 }
 
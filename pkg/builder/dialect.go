@@ -0,0 +1,385 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bhojpur/sql/pkg/sqllex"
+)
+
+// SQLDialect describes how a database flavours the SQL written by a Builder.
+// The built-in dialects are registered under POSTGRES, SQLITE, MYSQL, MSSQL and
+// ORACLE, others can be added using RegisterDialect.
+type SQLDialect interface {
+	// Name returns the name the dialect is registered under
+	Name() string
+	// Placeholder returns the bind parameter of the n-th (starting at 1) argument
+	Placeholder(n int) string
+	// BindArg returns the n-th (starting at 1) argument the way the driver expects it
+	BindArg(n int, arg interface{}) interface{}
+	// QuoteIdent quotes a single identifier, e.g. a table or column name
+	QuoteIdent(ident string) string
+	// Literal renders an argument inline, as used by ToBoundSQL
+	Literal(arg interface{}) string
+	// Pagination returns how LIMIT and OFFSET are written
+	Pagination() Pagination
+	// Supports reports whether the dialect supports all of the given features
+	Supports(feature Feature) bool
+}
+
+// NamedBinder is implemented by dialects whose drivers bind sql.NamedArg arguments by their names,
+// see Builder.NamedArgs
+type NamedBinder interface {
+	// NamedPlaceholder returns the bind parameter of the argument named name, or "" if the
+	// arguments are bound by their position after all
+	NamedPlaceholder(name string) string
+}
+
+// SyntaxDialect is implemented by dialects telling the lexical rules of their SQL, which find the
+// placeholders of a statement. The SQL of other dialects is lexed by sqllex.Generic.
+type SyntaxDialect interface {
+	// Syntax returns the lexical rules of the SQL of the dialect
+	Syntax() sqllex.Syntax
+}
+
+// syntaxOf returns the lexical rules of the SQL of dialect d
+func syntaxOf(d SQLDialect) sqllex.Syntax {
+	if sd, ok := d.(SyntaxDialect); ok {
+		return sd.Syntax()
+	}
+	return sqllex.Generic
+}
+
+// UpsertDialect is implemented by dialects telling how they handle inserts conflicting with
+// existing rows. Other dialects write ON CONFLICT.
+type UpsertDialect interface {
+	// Upsert returns how conflicting rows of an insert are handled
+	Upsert() UpsertStyle
+}
+
+// upsertOf returns how dialect d handles conflicting rows of an insert
+func upsertOf(d SQLDialect) UpsertStyle {
+	if ud, ok := d.(UpsertDialect); ok {
+		return ud.Upsert()
+	}
+	return UpsertOnConflict
+}
+
+// ArgsLimitDialect is implemented by dialects limiting the number of bind parameters of a
+// statement, which ToBatchSQL stays within. Other dialects have no limit.
+type ArgsLimitDialect interface {
+	// MaxArgs returns the maximum number of bind parameters of a statement, 0 if there is no limit
+	MaxArgs() int
+}
+
+// maxArgsOf returns the maximum number of bind parameters of a statement of dialect d, 0 if there is no limit
+func maxArgsOf(d SQLDialect) int {
+	if ad, ok := d.(ArgsLimitDialect); ok {
+		return ad.MaxArgs()
+	}
+	return 0
+}
+
+// RowsLimitDialect is implemented by dialects limiting the number of rows inserted by a single
+// VALUES clause, which ToBatchSQL stays within. Other dialects have no limit.
+type RowsLimitDialect interface {
+	// MaxInsertRows returns the maximum number of rows of a VALUES clause, 0 if there is no limit
+	MaxInsertRows() int
+}
+
+// maxInsertRowsOf returns the maximum number of rows of a VALUES clause of dialect d, 0 if there is no limit
+func maxInsertRowsOf(d SQLDialect) int {
+	if rd, ok := d.(RowsLimitDialect); ok {
+		return rd.MaxInsertRows()
+	}
+	return 0
+}
+
+// Pagination describes how a dialect limits the rows returned by a query
+type Pagination int
+
+const (
+	// PaginationLimitOffset appends LIMIT n OFFSET m
+	PaginationLimitOffset Pagination = iota
+	// PaginationRowNum wraps the query into ROWNUM comparisons (Oracle)
+	PaginationRowNum
+	// PaginationTopRowNumber wraps the query into SELECT TOP and ROW_NUMBER() (SQL Server)
+	PaginationTopRowNumber
+	// PaginationOffsetFetch appends OFFSET ... ROWS FETCH NEXT ... ROWS ONLY (SQL Server 2012, Oracle 12c)
+	PaginationOffsetFetch
+)
+
+// UpsertStyle describes how a dialect handles inserts conflicting with existing rows
+type UpsertStyle int
+
+const (
+	// UpsertOnConflict appends ON CONFLICT ... DO NOTHING or DO UPDATE (PostgreSQL, SQLite)
+	UpsertOnConflict UpsertStyle = iota
+	// UpsertOnDuplicateKey appends ON DUPLICATE KEY UPDATE (MySQL)
+	UpsertOnDuplicateKey
+	// UpsertMerge writes a MERGE statement (Oracle)
+	UpsertMerge
+	// UpsertMergeTSQL writes a MERGE statement holding its lock and terminated by a semicolon (SQL Server)
+	UpsertMergeTSQL
+)
+
+// Feature is a set of optional SQL features
+type Feature uint
+
+const (
+	// FeatureReturning supports a RETURNING clause on INSERT, UPDATE and DELETE
+	FeatureReturning Feature = 1 << iota
+	// FeatureOutput supports an OUTPUT clause on INSERT, UPDATE, DELETE and MERGE
+	FeatureOutput
+	// FeatureReturningInto supports RETURNING ... INTO out-binds for a single row
+	FeatureReturningInto
+	// FeatureNullsOrdering supports NULLS FIRST and NULLS LAST in ORDER BY
+	FeatureNullsOrdering
+	// FeatureRowValues supports comparing row values, e.g. (a,b)>(1,2)
+	FeatureRowValues
+	// FeatureWithRecursive requires the RECURSIVE keyword for recursive common table expressions
+	FeatureWithRecursive
+	// FeatureWithInsert supports common table expressions preceding INSERT, otherwise they
+	// precede the SELECT of INSERT ... SELECT
+	FeatureWithInsert
+	// FeatureWithUpdate supports common table expressions preceding UPDATE and DELETE
+	FeatureWithUpdate
+	// FeatureMultiRowInsert supports inserting several rows with a single VALUES clause
+	FeatureMultiRowInsert
+	// FeatureDualTable requires FROM DUAL to select values without a table
+	FeatureDualTable
+	// FeatureWindowClause supports defining named windows in a WINDOW clause
+	FeatureWindowClause
+	// FeatureOrderedOffset requires an ORDER BY clause for OFFSET
+	FeatureOrderedOffset
+)
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]SQLDialect)
+)
+
+// RegisterDialect makes a dialect available to Builder by its name.
+// If RegisterDialect is called twice with the same name or if dialect is nil, it panics.
+func RegisterDialect(dialect SQLDialect) {
+	if dialect == nil {
+		panic("builder: RegisterDialect dialect is nil")
+	}
+	name := normalizeDialectName(dialect.Name())
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if _, dup := dialects[name]; dup {
+		panic("builder: RegisterDialect called twice for dialect " + name)
+	}
+	dialects[name] = dialect
+}
+
+// GetDialect returns the dialect registered under name or nil if there is none
+func GetDialect(name string) SQLDialect {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	return dialects[normalizeDialectName(name)]
+}
+
+// Dialects returns the sorted names of the registered dialects
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requireDialect returns the dialect of the builder or an error if it is not set up or unknown
+func (b *Builder) requireDialect() (SQLDialect, error) {
+	if strings.TrimSpace(b.dialect) == "" {
+		return nil, ErrDialectNotSetUp
+	}
+	d := GetDialect(b.dialect)
+	if d == nil {
+		return nil, ErrNotSupportDialectType
+	}
+	return d, nil
+}
+
+func normalizeDialectName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// builtinDialect is the SQLDialect implementation of the databases supported out of the box
+type builtinDialect struct {
+	name string
+	// placeholderPrefix is followed by the argument's number, no prefix writes ?
+	placeholderPrefix string
+	// namedArgs passes the arguments as sql.Named("p1", ...), sql.Named("p2", ...) ...
+	namedArgs bool
+	// namedPrefix is followed by the name of a named argument, see NamedPlaceholder
+	namedPrefix string
+	quoteOpen   string
+	quoteClose  string
+	pagination  Pagination
+	upsert      UpsertStyle
+	maxArgs     int
+	// maxInsertRows limits the rows of a VALUES clause, see MaxInsertRows
+	maxInsertRows int
+	features      Feature
+	syntax        sqllex.Syntax
+}
+
+func (d *builtinDialect) Name() string {
+	return d.name
+}
+
+func (d *builtinDialect) Placeholder(n int) string {
+	if d.placeholderPrefix == "" {
+		return "?"
+	}
+	return fmt.Sprintf("%s%d", d.placeholderPrefix, n)
+}
+
+func (d *builtinDialect) BindArg(n int, arg interface{}) interface{} {
+	if !d.namedArgs {
+		return arg
+	}
+	// This is for compatibility with different sql drivers
+	return sql2.Named(fmt.Sprintf("p%d", n), arg)
+}
+
+func (d *builtinDialect) NamedPlaceholder(name string) string {
+	if d.namedPrefix == "" {
+		return ""
+	}
+	return d.namedPrefix + name
+}
+
+func (d *builtinDialect) Syntax() sqllex.Syntax {
+	return d.syntax
+}
+
+func (d *builtinDialect) QuoteIdent(ident string) string {
+	return d.quoteOpen + strings.Replace(ident, d.quoteClose, d.quoteClose+d.quoteClose, -1) + d.quoteClose
+}
+
+func (d *builtinDialect) Literal(arg interface{}) string {
+	return literal(arg)
+}
+
+func (d *builtinDialect) Pagination() Pagination {
+	return d.pagination
+}
+
+func (d *builtinDialect) Upsert() UpsertStyle {
+	return d.upsert
+}
+
+func (d *builtinDialect) MaxArgs() int {
+	return d.maxArgs
+}
+
+func (d *builtinDialect) MaxInsertRows() int {
+	return d.maxInsertRows
+}
+
+func (d *builtinDialect) Supports(feature Feature) bool {
+	return d.features&feature == feature
+}
+
+func init() {
+	RegisterDialect(&builtinDialect{
+		name:              POSTGRES,
+		placeholderPrefix: "$",
+		quoteOpen:         `"`,
+		quoteClose:        `"`,
+		pagination:        PaginationLimitOffset,
+		upsert:            UpsertOnConflict,
+		maxArgs:           65535,
+		features: FeatureReturning | FeatureNullsOrdering | FeatureRowValues | FeatureMultiRowInsert |
+			FeatureWithRecursive | FeatureWithInsert | FeatureWithUpdate | FeatureWindowClause,
+		syntax: sqllex.PostgreSQL,
+	})
+	RegisterDialect(&builtinDialect{
+		name:       SQLITE,
+		quoteOpen:  `"`,
+		quoteClose: `"`,
+		pagination: PaginationLimitOffset,
+		upsert:     UpsertOnConflict,
+		maxArgs:    999, // 32766 since SQLite 3.32.0, see Builder.MaxArgs
+		features: FeatureReturning | FeatureNullsOrdering | FeatureRowValues | FeatureMultiRowInsert |
+			FeatureWithRecursive | FeatureWithInsert | FeatureWithUpdate | FeatureWindowClause,
+		syntax: sqllex.SQLite,
+	})
+	RegisterDialect(&builtinDialect{
+		name:       MYSQL,
+		quoteOpen:  "`",
+		quoteClose: "`",
+		pagination: PaginationLimitOffset,
+		upsert:     UpsertOnDuplicateKey,
+		maxArgs:    65535,
+		features: FeatureRowValues | FeatureMultiRowInsert | FeatureWithRecursive | FeatureWithUpdate |
+			FeatureWindowClause,
+		syntax: sqllex.MySQL,
+	})
+	mssql := &builtinDialect{
+		name:              MSSQL,
+		placeholderPrefix: "@p",
+		namedArgs:         true,
+		namedPrefix:       "@",
+		quoteOpen:         "[",
+		quoteClose:        "]",
+		pagination:        PaginationTopRowNumber,
+		upsert:            UpsertMergeTSQL,
+		maxArgs:           2100,
+		maxInsertRows:     1000,
+		features: FeatureOutput | FeatureMultiRowInsert | FeatureWithInsert | FeatureWithUpdate |
+			FeatureOrderedOffset,
+		syntax: sqllex.SQLServer,
+	}
+	RegisterDialect(mssql)
+	mssql2012 := *mssql
+	mssql2012.name, mssql2012.pagination = MSSQL2012, PaginationOffsetFetch
+	RegisterDialect(&mssql2012)
+
+	oracle := &builtinDialect{
+		name:              ORACLE,
+		placeholderPrefix: ":p",
+		namedArgs:         true,
+		namedPrefix:       ":",
+		quoteOpen:         `"`,
+		quoteClose:        `"`,
+		pagination:        PaginationRowNum,
+		upsert:            UpsertMerge,
+		maxArgs:           65535,
+		features:          FeatureReturningInto | FeatureNullsOrdering | FeatureDualTable,
+		syntax:            sqllex.Oracle,
+	}
+	RegisterDialect(oracle)
+	oracle12 := *oracle
+	oracle12.name, oracle12.pagination = ORACLE12, PaginationOffsetFetch
+	RegisterDialect(&oracle12)
+}
@@ -0,0 +1,170 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bhojpur/sql/pkg/sqllex"
+	"github.com/stretchr/testify/assert"
+)
+
+// duckDB is a dialect as a third party would add it, borrowing from a built-in one
+type duckDB struct {
+	SQLDialect
+}
+
+func (duckDB) Name() string {
+	return "duckdb-test"
+}
+
+func (duckDB) Literal(arg interface{}) string {
+	if b, ok := arg.(bool); ok {
+		return strings.ToUpper(fmt.Sprint(b))
+	}
+	return GetDialect(POSTGRES).Literal(arg)
+}
+
+// legacyDB numbers its placeholders without a prefix and paginates like Oracle
+type legacyDB struct {
+	SQLDialect
+}
+
+func (legacyDB) Name() string {
+	return "legacy-test"
+}
+
+func (legacyDB) Placeholder(n int) string {
+	return fmt.Sprintf(":%d", n)
+}
+
+func (legacyDB) BindArg(n int, arg interface{}) interface{} {
+	return arg
+}
+
+func init() {
+	RegisterDialect(duckDB{GetDialect(POSTGRES)})
+	RegisterDialect(legacyDB{GetDialect(ORACLE)})
+}
+
+func TestBuiltinDialects(t *testing.T) {
+	assert.EqualValues(t, []string{"duckdb-test", "legacy-test", MSSQL, MSSQL2012, MYSQL, ORACLE, ORACLE12, POSTGRES, SQLITE}, Dialects())
+
+	quoted := map[string]string{
+		POSTGRES: `"order"`,
+		SQLITE:   `"order"`,
+		ORACLE:   `"order"`,
+		MYSQL:    "`order`",
+		MSSQL:    "[order]",
+	}
+	for name, expected := range quoted {
+		d := GetDialect(name)
+		if !assert.NotNil(t, d, name) {
+			continue
+		}
+		assert.EqualValues(t, name, d.Name())
+		assert.EqualValues(t, expected, d.QuoteIdent("order"), name)
+	}
+	assert.EqualValues(t, `"a""b"`, GetDialect(POSTGRES).QuoteIdent(`a"b`))
+	assert.EqualValues(t, "[a]]b]", GetDialect(MSSQL).QuoteIdent("a]b"))
+
+	assert.EqualValues(t, "$2", GetDialect(POSTGRES).Placeholder(2))
+	assert.EqualValues(t, "?", GetDialect(MYSQL).Placeholder(2))
+	assert.EqualValues(t, "@p2", GetDialect(MSSQL).Placeholder(2))
+	assert.EqualValues(t, sql2.Named("p2", 1), GetDialect(ORACLE).BindArg(2, 1))
+	assert.EqualValues(t, 1, GetDialect(SQLITE).BindArg(2, 1))
+	assert.EqualValues(t, ":uid", GetDialect(ORACLE).(NamedBinder).NamedPlaceholder("uid"))
+	assert.EqualValues(t, "@uid", GetDialect(MSSQL2012).(NamedBinder).NamedPlaceholder("uid"))
+	assert.EqualValues(t, "", GetDialect(POSTGRES).(NamedBinder).NamedPlaceholder("uid"))
+	assert.EqualValues(t, sqllex.SQLServer, GetDialect(MSSQL2012).(SyntaxDialect).Syntax())
+	assert.EqualValues(t, sqllex.Generic, syntaxOf(GetDialect("duckdb-test")))
+
+	assert.EqualValues(t, PaginationLimitOffset, GetDialect(MYSQL).Pagination())
+	assert.EqualValues(t, PaginationTopRowNumber, GetDialect(MSSQL).Pagination())
+	assert.EqualValues(t, PaginationRowNum, GetDialect(ORACLE).Pagination())
+
+	assert.EqualValues(t, UpsertOnConflict, GetDialect(SQLITE).(UpsertDialect).Upsert())
+	assert.EqualValues(t, UpsertOnDuplicateKey, GetDialect(MYSQL).(UpsertDialect).Upsert())
+	assert.EqualValues(t, UpsertMergeTSQL, GetDialect(MSSQL).(UpsertDialect).Upsert())
+	assert.EqualValues(t, UpsertOnConflict, upsertOf(GetDialect("duckdb-test")))
+	assert.EqualValues(t, 2100, GetDialect(MSSQL).(ArgsLimitDialect).MaxArgs())
+	assert.EqualValues(t, 0, maxArgsOf(GetDialect("duckdb-test")))
+	assert.EqualValues(t, 1000, GetDialect(MSSQL2012).(RowsLimitDialect).MaxInsertRows())
+	assert.EqualValues(t, 0, maxInsertRowsOf(GetDialect(POSTGRES)))
+
+	assert.True(t, GetDialect(POSTGRES).Supports(FeatureReturning|FeatureNullsOrdering))
+	assert.False(t, GetDialect(MYSQL).Supports(FeatureReturning|FeatureRowValues))
+	assert.True(t, GetDialect(MYSQL).Supports(FeatureRowValues))
+
+	assert.NotNil(t, GetDialect(" MySQL "))
+	assert.Nil(t, GetDialect("unknown"))
+}
+
+func TestRegisterDialect(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterDialect(nil)
+	})
+	assert.Panics(t, func() {
+		RegisterDialect(duckDB{GetDialect(POSTGRES)})
+	})
+}
+
+func TestCustomDialect(t *testing.T) {
+	sql, args, err := Dialect("duckdb-test").Select("a").From("table1").
+		Where(Eq{"a": 1, "b": true}).Limit(5, 10).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 WHERE a=$1 AND b=$2 LIMIT 5 OFFSET 10", sql)
+	assert.EqualValues(t, []interface{}{1, true}, args)
+
+	sql, err = Dialect("duckdb-test").Select("a").From("table1").
+		Where(Eq{"a": "x", "b": true}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 WHERE a='x' AND b=TRUE", sql)
+
+	sql, args, err = Dialect("legacy-test").Select("a").From("table1").
+		Where(Eq{"a": 1}).Limit(5).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT * FROM (SELECT a FROM table1 WHERE a=:1) at WHERE ROWNUM<=:2", sql)
+	assert.EqualValues(t, []interface{}{1, 5}, args)
+
+	// dialects implementing only SQLDialect upsert by ON CONFLICT and batch without a limit
+	sql, err = Dialect("duckdb-test").Insert(Eq{"a": 1}).Into("table1").OnConflict("a").DoNothing().ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO table1 (a) Values (1) ON CONFLICT (a) DO NOTHING", sql)
+	statements, err := Dialect("duckdb-test").Into("table1").InsertRows(Eq{"a": 1}, Eq{"a": 2}).ToBatchSQL()
+	assert.NoError(t, err)
+	assert.Len(t, statements, 1)
+}
+
+func TestUnknownDialect(t *testing.T) {
+	sql, args, err := Dialect("unknown").Select("a").From("table1").Where(Eq{"a": 1}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a FROM table1 WHERE a=?", sql)
+	assert.EqualValues(t, []interface{}{1}, args)
+
+	_, _, err = Dialect("unknown").Select("a").From("table1").Limit(5).ToSQL()
+	assert.EqualValues(t, ErrNotSupportDialectType, err)
+	_, _, err = Select("a").From("table1").Limit(5).ToSQL()
+	assert.EqualValues(t, ErrDialectNotSetUp, err)
+}
@@ -51,6 +51,38 @@ var (
 	ErrInvalidLimitation = errors.New("Offset or limit is not correct")
 	// ErrUnnamedDerivedTable Every derived table must have its own alias
 	ErrUnnamedDerivedTable = errors.New("Every derived table must have its own alias")
+	// ErrUnnamedPagedColumn an expression without alias selected by a query paged by ROW_NUMBER() or ROWNUM
+	ErrUnnamedPagedColumn = errors.New("Selected expressions need an alias to page by ROW_NUMBER() or ROWNUM")
 	// ErrInconsistentDialect Inconsistent dialect in same builder
 	ErrInconsistentDialect = errors.New("Inconsistent dialect in same builder")
+	// ErrInconsistentRows inserted rows with different columns
+	ErrInconsistentRows = errors.New("Inserted rows must have the same columns")
+	// ErrTooManyArguments a single row needs more arguments than the dialect allows
+	ErrTooManyArguments = errors.New("Too many sql arguments for a single statement")
+	// ErrNotSupportReturning returning columns is not supported by the dialect or the query
+	ErrNotSupportReturning = errors.New("Returning columns is not supported by the dialect or query")
+	// ErrNotSupportCTE common table expressions are not supported by the dialect or query
+	ErrNotSupportCTE = errors.New("Common table expressions are not supported by the dialect or query")
+	// ErrNoConflictTarget no columns identifying the conflicting rows of an upsert
+	ErrNoConflictTarget = errors.New("No conflict target column(s) to upsert")
+	// ErrUnexpectedExcluded Excluded used outside of an upsert
+	ErrUnexpectedExcluded = errors.New("Excluded can only be used to update conflicting rows of an upsert")
+	// ErrUnsupportedUpsert upsert of an INSERT ... SELECT query
+	ErrUnsupportedUpsert = errors.New("Upsert is not supported by INSERT ... SELECT query")
+	// ErrNotStruct mapping a value which is not a struct to columns
+	ErrNotStruct = errors.New("Only structs or pointers to structs can be mapped to columns")
+	// ErrNoPrimaryKey no field of a struct is tagged as primary key
+	ErrNoPrimaryKey = errors.New("No field tagged as primary key")
+	// ErrNotSlice scanning rows into a value which is not a pointer to a slice
+	ErrNotSlice = errors.New("Rows can only be scanned into a pointer to a slice")
+	// ErrInvalidCursor a keyset cursor which cannot be decoded or does not match the keys
+	ErrInvalidCursor = errors.New("Invalid keyset cursor")
+	// ErrBoundOutArg an output argument, e.g. of RETURNING ... INTO, written inline by ToBoundSQL
+	ErrBoundOutArg = errors.New("Output arguments cannot be bound into the SQL")
+	// ErrInvalidWindowRefinement a window overriding the partitioning, ordering or frame of its base window
+	ErrInvalidWindowRefinement = errors.New("Window cannot override the partitioning, ordering or frame of its base window")
+	// ErrRecursiveWindow named windows based on each other
+	ErrRecursiveWindow = errors.New("Named windows cannot be based on each other")
+	// ErrConflictingNamedArg one name given to arguments of different values
+	ErrConflictingNamedArg = errors.New("Named arguments of the same name must have the same value")
 )
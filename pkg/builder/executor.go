@@ -0,0 +1,234 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// DB runs SQL statements. It is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Executor runs Builders against a database and scans the rows they return
+// into structs mapped like StructColumns does.
+type Executor struct {
+	db DB
+}
+
+// NewExecutor creates an Executor running statements on db
+func NewExecutor(db DB) *Executor {
+	return &Executor{db: db}
+}
+
+// Exec runs the statement of b which returns no rows
+func (e *Executor) Exec(ctx context.Context, b *Builder) (sql.Result, error) {
+	query, args, err := b.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	return e.db.ExecContext(ctx, query, args...)
+}
+
+// Query runs the query of b and returns its rows to iterate over
+func (e *Executor) Query(ctx context.Context, b *Builder) (*Rows, error) {
+	query, args, err := b.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{Rows: rows}, nil
+}
+
+// Get runs the query of b and scans its first row into dest, which points to a struct or,
+// for a single column, to any value sql.Rows.Scan accepts. It returns sql.ErrNoRows if there is none.
+func (e *Executor) Get(ctx context.Context, dest interface{}, b *Builder) error {
+	rows, err := e.Query(ctx, b)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.ScanStruct(dest); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Select runs the query of b and appends all rows to the slice dest points to.
+// Its elements are structs, pointers to structs or single column values.
+func (e *Executor) Select(ctx context.Context, dest interface{}, b *Builder) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return ErrNotSlice
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	rows, err := e.Query(ctx, b)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := rows.ScanStruct(elem.Interface()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Rows are the rows of a query run by Executor.Query
+type Rows struct {
+	*sql.Rows
+	cols []string
+}
+
+// ScanStruct scans the current row into dest, which points to a struct or, for a single column,
+// to any value sql.Rows.Scan accepts. Columns are assigned to the fields they are mapped to,
+// columns without a field are skipped. NULL is scanned into non-pointer fields as zero value.
+func (r *Rows) ScanStruct(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrNotStruct
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct || isScanner(rv.Addr().Type()) {
+		return r.Scan(dest)
+	}
+	fields := structFields(rv.Type())
+	if len(fields) == 0 {
+		// e.g. time.Time
+		return r.Scan(dest)
+	}
+
+	if r.cols == nil {
+		cols, err := r.Columns()
+		if err != nil {
+			return err
+		}
+		r.cols = cols
+	}
+	targets := make([]interface{}, len(r.cols))
+	var nullables []nullable
+	for i, col := range r.cols {
+		f, ok := fieldOfColumn(fields, col)
+		if !ok {
+			targets[i] = new(interface{})
+			continue
+		}
+		fv, ok := allocFieldByIndex(rv, f.index)
+		if !ok {
+			targets[i] = new(interface{})
+			continue
+		}
+		if fv.Kind() == reflect.Ptr || isScanner(fv.Addr().Type()) {
+			targets[i] = fv.Addr().Interface()
+			continue
+		}
+		// sql.Rows.Scan sets a pointer to nil for NULL
+		ptr := reflect.New(reflect.PtrTo(fv.Type()))
+		nullables = append(nullables, nullable{field: fv, ptr: ptr})
+		targets[i] = ptr.Interface()
+	}
+	if err := r.Scan(targets...); err != nil {
+		return err
+	}
+	for _, n := range nullables {
+		if p := n.ptr.Elem(); p.IsNil() {
+			n.field.Set(reflect.Zero(n.field.Type()))
+		} else {
+			n.field.Set(p.Elem())
+		}
+	}
+	return nil
+}
+
+type nullable struct {
+	field reflect.Value
+	ptr   reflect.Value
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+func isScanner(t reflect.Type) bool {
+	return t.Implements(scannerType)
+}
+
+// fieldOfColumn returns the field col is mapped to, ignoring the case of col
+// as some databases return upper case column names
+func fieldOfColumn(fields []structField, col string) (structField, bool) {
+	for _, f := range fields {
+		if f.col == col {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.col, col) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+// allocFieldByIndex returns the field of rv at index, allocating the embedded structs which are nil.
+// It returns false if such a struct cannot be allocated because it is unexported.
+func allocFieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
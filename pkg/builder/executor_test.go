@@ -0,0 +1,190 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	sql2 "database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver returns the rows of its next result to any query and records the statements
+type fakeDriver struct {
+	cols       []string
+	rows       [][]driver.Value
+	statements []string
+	args       [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeConn{c.d}, nil }
+func (c fakeConn) Commit() error                             { return nil }
+func (c fakeConn) Rollback() error                           { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.statements, s.d.args = append(s.d.statements, s.query), append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.statements, s.d.args = append(s.d.statements, s.query), append(s.d.args, args)
+	return &fakeRows{cols: s.d.cols, rows: s.d.rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql2.Register("builder-fake", testDriver)
+}
+
+func openFakeDB(t *testing.T, cols []string, rows ...[]driver.Value) *sql2.DB {
+	testDriver.cols, testDriver.rows, testDriver.statements, testDriver.args = cols, rows, nil, nil
+	db, err := sql2.Open("builder-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type scanned struct {
+	ID      int64   `db:"id,pk"`
+	Name    string  `db:"name"`
+	Email   *string `db:"email"`
+	Note    sql2.NullString
+	Created time.Time `db:"created"`
+	*Team
+}
+
+type Team struct {
+	TeamName string
+}
+
+func TestExecutor_Select(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	db := openFakeDB(t, []string{"ID", "name", "email", "note", "created", "team_name", "RN"},
+		[]driver.Value{int64(1), "cat", nil, "n", created, "dog", int64(1)},
+		[]driver.Value{int64(2), nil, "bird@example.com", nil, created, nil, int64(2)},
+	)
+	e := NewExecutor(db)
+	ctx := context.Background()
+
+	var res []scanned
+	err := e.Select(ctx, &res, Postgres().Select("*").From("account").Where(Gt{"id": 0}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"SELECT * FROM account WHERE id>$1"}, testDriver.statements)
+	assert.EqualValues(t, [][]driver.Value{{int64(0)}}, testDriver.args)
+	email := "bird@example.com"
+	assert.EqualValues(t, []scanned{
+		{ID: 1, Name: "cat", Note: sql2.NullString{String: "n", Valid: true}, Created: created, Team: &Team{TeamName: "dog"}},
+		{ID: 2, Email: &email, Created: created, Team: &Team{}},
+	}, res)
+
+	var ptrs []*scanned
+	db = openFakeDB(t, []string{"id"}, []driver.Value{int64(3)})
+	assert.NoError(t, NewExecutor(db).Select(ctx, &ptrs, Select("id").From("account")))
+	assert.Len(t, ptrs, 1)
+	assert.EqualValues(t, 3, ptrs[0].ID)
+
+	var ids []int64
+	db = openFakeDB(t, []string{"id"}, []driver.Value{int64(3)}, []driver.Value{int64(4)})
+	assert.NoError(t, NewExecutor(db).Select(ctx, &ids, Select("id").From("account")))
+	assert.EqualValues(t, []int64{3, 4}, ids)
+
+	assert.EqualValues(t, ErrNotSlice, e.Select(ctx, res, Select("id").From("account")))
+	_, err = e.Query(ctx, Select("id"))
+	assert.EqualValues(t, ErrNoTableName, err)
+}
+
+func TestExecutor_Get(t *testing.T) {
+	ctx := context.Background()
+	db := openFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "cat"})
+
+	var res scanned
+	assert.NoError(t, NewExecutor(db).Get(ctx, &res, Select("id", "name").From("account").Where(Eq{"id": 1})))
+	assert.EqualValues(t, scanned{ID: 1, Name: "cat"}, res)
+
+	var count int
+	db = openFakeDB(t, []string{"count"}, []driver.Value{int64(5)})
+	assert.NoError(t, NewExecutor(db).Get(ctx, &count, Select("count(*)").From("account")))
+	assert.EqualValues(t, 5, count)
+
+	db = openFakeDB(t, []string{"id"})
+	assert.EqualValues(t, sql2.ErrNoRows, NewExecutor(db).Get(ctx, &res, Select("id").From("account")))
+}
+
+func TestExecutor_Exec(t *testing.T) {
+	ctx := context.Background()
+	db := openFakeDB(t, nil)
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	res, err := NewExecutor(tx).Exec(ctx, Postgres().Update(Eq{"name": "cat"}).From("account").Where(Eq{"id": 1}))
+	assert.NoError(t, err)
+	n, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.EqualValues(t, []string{"UPDATE account SET name=$1 WHERE id=$2"}, testDriver.statements)
+	assert.NoError(t, tx.Commit())
+
+	rows, err := NewExecutor(openFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "cat"})).
+		Query(ctx, Select("id", "name").From("account"))
+	assert.NoError(t, err)
+	defer rows.Close()
+	var res2 scanned
+	for rows.Next() {
+		assert.NoError(t, rows.ScanStruct(&res2))
+	}
+	assert.NoError(t, rows.Err())
+	assert.EqualValues(t, "cat", res2.Name)
+}
@@ -0,0 +1,74 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// identSegment matches an unquoted or an already quoted identifier
+	identSegment = "(?:[A-Za-z_][A-Za-z0-9_$#]*|\"(?:[^\"]|\"\")*\"|`(?:[^`]|``)*`|\\[(?:[^\\]]|\\]\\])*\\])"
+	identPath    = regexp.MustCompile(`^` + identSegment + `(?:\.` + identSegment + `)*(?:\.\*)?$`)
+	identSplit   = regexp.MustCompile(identSegment + `|\*`)
+	identAlias   = regexp.MustCompile(`(?i)^(.+?)\s+(?:AS\s+)?(` + identSegment + `)$`)
+	orderTerm    = regexp.MustCompile(`(?i)^(.+?)((?:\s+(?:ASC|DESC))?(?:\s+NULLS\s+(?:FIRST|LAST))?)$`)
+)
+
+// quoteIdentExpr quotes the identifiers of a table or column reference like table, table t,
+// schema.table, t.column, t.* or column AS alias. Anything else, e.g. expressions or
+// function calls, is returned as it is.
+func quoteIdentExpr(dialect SQLDialect, s string) string {
+	ref := strings.TrimSpace(s)
+	if ref == "" || ref == "*" {
+		return s
+	}
+	if identPath.MatchString(ref) {
+		return quoteIdentPath(dialect, ref)
+	}
+	m := identAlias.FindStringSubmatch(ref)
+	if m == nil || !identPath.MatchString(m[1]) || strings.EqualFold(m[1], "DISTINCT") {
+		return s
+	}
+	return quoteIdentPath(dialect, m[1]) + ref[len(m[1]):len(ref)-len(m[2])] + quoteIdentPath(dialect, m[2])
+}
+
+// quoteIdentPath quotes every unquoted segment of a dotted reference
+func quoteIdentPath(dialect SQLDialect, path string) string {
+	return identSplit.ReplaceAllStringFunc(path, func(segment string) string {
+		switch segment[0] {
+		case '*', '"', '`', '[':
+			return segment
+		}
+		return dialect.QuoteIdent(segment)
+	})
+}
+
+// quoteOrderExpr quotes the column of an order by term like t.column DESC NULLS LAST.
+// Expressions are returned as they are.
+func quoteOrderExpr(dialect SQLDialect, s string) string {
+	m := orderTerm.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || !identPath.MatchString(m[1]) {
+		return s
+	}
+	return quoteIdentPath(dialect, m[1]) + m[2]
+}
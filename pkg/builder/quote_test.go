@@ -0,0 +1,117 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteIdentExpr(t *testing.T) {
+	d := GetDialect(POSTGRES)
+	cases := map[string]string{
+		"order":                  `"order"`,
+		"user.name":              `"user"."name"`,
+		"public.user.name":       `"public"."user"."name"`,
+		"u.*":                    `"u".*`,
+		"*":                      "*",
+		"user u":                 `"user" "u"`,
+		"u.name AS UserName":     `"u"."name" AS "UserName"`,
+		"u.name as n":            `"u"."name" as "n"`,
+		`"Mixed".name`:           `"Mixed"."name"`,
+		"count(*)":               "count(*)",
+		"count(*) AS n":          "count(*) AS n",
+		"a+1":                    "a+1",
+		"DISTINCT a":             "DISTINCT a",
+		"a, b":                   "a, b",
+		"CASE WHEN a THEN b END": "CASE WHEN a THEN b END",
+	}
+	for in, expected := range cases {
+		assert.EqualValues(t, expected, quoteIdentExpr(d, in), in)
+	}
+	assert.EqualValues(t, "`user`.`name`", quoteIdentExpr(GetDialect(MYSQL), "user.name"))
+	assert.EqualValues(t, "[user].*", quoteIdentExpr(GetDialect(MSSQL), "user.*"))
+}
+
+func TestQuoteOrderExpr(t *testing.T) {
+	d := GetDialect(MYSQL)
+	assert.EqualValues(t, "`a`", quoteOrderExpr(d, "a"))
+	assert.EqualValues(t, "`t`.`a` desc", quoteOrderExpr(d, "t.a desc"))
+	assert.EqualValues(t, "`a` ASC NULLS FIRST", quoteOrderExpr(d, "a ASC NULLS FIRST"))
+	assert.EqualValues(t, "length(a) DESC", quoteOrderExpr(d, "length(a) DESC"))
+}
+
+func TestBuilder_QuoteIdents(t *testing.T) {
+	sql, args, err := Postgres().QuoteIdents().Select("u.id", "u.*", "count(*) AS n").From("user", "u").
+		LeftJoin("order o", "o.user_id = u.id").
+		Where(Eq{"u.name": "cat"}.And(Like{"o.desc", "x"}, In("o.state", 1, 2), IsNull{"o.deleted"})).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT "u"."id","u".*,count(*) AS n FROM "user" "u" LEFT JOIN "order" "o" ON o.user_id = u.id WHERE "u"."name"=$1 AND "o"."desc" LIKE $2 AND "o"."state" IN ($3,$4) AND "o"."deleted" IS NULL`, sql)
+	assert.EqualValues(t, []interface{}{"cat", "%x%", 1, 2}, args)
+
+	sql, err = MySQL().QuoteIdents().Select("id").From(Select("id").From("order").Where(Gt{"id": 1}), "sub").
+		Where(Eq{"sub.id": 2}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT `id` FROM (SELECT `id` FROM `order` WHERE `id`>1) `sub` WHERE `sub`.`id`=2", sql)
+
+	sql, args, err = MsSQL().QuoteIdents().Insert(Eq{"order": 1, "user": Expr("?+1", 2)}).Into("dbo.table").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO [dbo].[table] ([order],[user]) Values (@p1,(@p2+1))", sql)
+	assert.EqualValues(t, 2, len(args))
+
+	sql, err = SQLite().QuoteIdents().Insert("a", "b").Into("table1").Select("c", "d").From("table2").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `INSERT INTO "table1" ("a","b") SELECT "c","d" FROM "table2"`, sql)
+
+	sql, err = Oracle().QuoteIdents().Update(Eq{"user": 1, "count": Incr(1)}).From("order").Where(Eq{"id": 2}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `UPDATE "order" SET "count"="count"+1,"user"=1 WHERE "id"=2`, sql)
+
+	sql, err = MySQL().QuoteIdents().Delete(Eq{"group": 1}).From("order").ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "DELETE FROM `order` WHERE `group`=1", sql)
+
+	// identifiers are written as they are without QuoteIdents
+	sql, err = MySQL().Select("order").From("user").Where(Eq{"group": 1}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT order FROM user WHERE group=1", sql)
+
+	_, err = Select("a").From("user").QuoteIdents().ToBoundSQL()
+	assert.EqualValues(t, ErrDialectNotSetUp, err)
+	_, err = Dialect("unknown").Select("a").From("user").QuoteIdents().ToBoundSQL()
+	assert.EqualValues(t, ErrNotSupportDialectType, err)
+}
+
+func TestBuilder_QuoteIdentsWithLimit(t *testing.T) {
+	sql, err := Oracle().QuoteIdents().Select("a", "b").From("order").OrderBy("a ASC").Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT "a","b" FROM (SELECT "at".*,ROWNUM RN FROM (SELECT "a","b" FROM "order" ORDER BY a ASC) "at" WHERE ROWNUM<=15) "att" WHERE "att"."RN">10`, sql)
+
+	sql, err = MsSQL().QuoteIdents().Select("a", "b").From("order").OrderBy("a ASC").Limit(5, 10).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT TOP 5 [a],[b] FROM (SELECT [a],[b],ROW_NUMBER() OVER (ORDER BY a ASC) AS RN FROM [order]) [at] WHERE [at].[RN]>10 ORDER BY [at].[RN] ASC", sql)
+
+	sql, err = Postgres().QuoteIdents().Select("a").From("order").Union("ALL", Select("a").From("user")).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `(SELECT "a" FROM "order") UNION ALL (SELECT "a" FROM "user")`, sql)
+}
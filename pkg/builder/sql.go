@@ -26,6 +26,8 @@ import (
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/bhojpur/sql/pkg/sqllex"
 )
 
 func condToSQL(cond Cond) (string, []interface{}, error) {
@@ -106,15 +108,26 @@ func noSQLQuoteNeeded(a interface{}) bool {
 
 // ConvertToBoundSQL will convert SQL and args to a bound SQL
 func ConvertToBoundSQL(sql string, args []interface{}) (string, error) {
+	return convertToBoundSQL(sql, args, sqllex.Generic, literal)
+}
+
+// literal renders an argument inline, numbers and booleans as they are and everything else quoted
+func literal(arg interface{}) string {
+	if noSQLQuoteNeeded(arg) {
+		return fmt.Sprint(arg)
+	}
+	// replace ' -> '' (standard replacement) to avoid critical SQL injection,
+	// NOTICE: may allow some injection like % (or _) in LIKE query
+	return fmt.Sprintf("'%v'", strings.Replace(fmt.Sprintf("%v", arg), "'", "''", -1))
+}
+
+func convertToBoundSQL(sql string, args []interface{}, syntax sqllex.Syntax, literal func(interface{}) string) (string, error) {
 	buf := strings.Builder{}
-	var i, j, start int
-	for ; i < len(sql); i++ {
-		if sql[i] == '?' {
-			_, err := buf.WriteString(sql[start:i])
-			if err != nil {
-				return "", err
-			}
-			start = i + 1
+	var j int
+	for _, t := range sqllex.Lex(sql, syntax) {
+		text := t.Text
+		switch t.Kind {
+		case sqllex.Placeholder:
 			if len(args) == j {
 				return "", ErrNeedMoreArguments
 			}
@@ -122,49 +135,51 @@ func ConvertToBoundSQL(sql string, args []interface{}) (string, error) {
 			if namedArg, ok := arg.(sql2.NamedArg); ok {
 				arg = namedArg.Value
 			}
-			if noSQLQuoteNeeded(arg) {
-				_, err = fmt.Fprint(&buf, arg)
-			} else {
-				// replace ' -> '' (standard replacement) to avoid critical SQL injection,
-				// NOTICE: may allow some injection like % (or _) in LIKE query
-				_, err = fmt.Fprintf(&buf, "'%v'", strings.Replace(fmt.Sprintf("%v", arg), "'",
-					"''", -1))
-			}
-			if err != nil {
-				return "", err
+			if _, ok := arg.(sql2.Out); ok {
+				return "", ErrBoundOutArg
 			}
+			text = literal(arg)
 			j = j + 1
+		case sqllex.QuestionOperator:
+			text = questionOperator(text)
+		}
+		if _, err := buf.WriteString(text); err != nil {
+			return "", err
 		}
-	}
-	_, err := buf.WriteString(sql[start:])
-	if err != nil {
-		return "", err
 	}
 	return buf.String(), nil
 }
 
 // ConvertPlaceholder replaces the place holder ? to $1, $2 ... or :1, :2 ... according prefix
 func ConvertPlaceholder(sql, prefix string) (string, error) {
+	return convertPlaceholder(sql, sqllex.Generic, func(n int) string {
+		return fmt.Sprintf("%v%d", prefix, n)
+	})
+}
+
+func convertPlaceholder(sql string, syntax sqllex.Syntax, placeholder func(n int) string) (string, error) {
 	buf := strings.Builder{}
-	var i, j, start int
-	var ready = true
-	for ; i < len(sql); i++ {
-		if sql[i] == '\'' && i > 0 && sql[i-1] != '\\' {
-			ready = !ready
-		}
-		if ready && sql[i] == '?' {
-			if _, err := buf.WriteString(sql[start:i]); err != nil {
-				return "", err
-			}
-			start = i + 1
+	var j int
+	for _, t := range sqllex.Lex(sql, syntax) {
+		text := t.Text
+		switch t.Kind {
+		case sqllex.Placeholder:
 			j = j + 1
-			if _, err := buf.WriteString(fmt.Sprintf("%v%d", prefix, j)); err != nil {
-				return "", err
-			}
+			text = placeholder(j)
+		case sqllex.QuestionOperator:
+			text = questionOperator(text)
+		}
+		if _, err := buf.WriteString(text); err != nil {
+			return "", err
 		}
-	}
-	if _, err := buf.WriteString(sql[start:]); err != nil {
-		return "", err
 	}
 	return buf.String(), nil
 }
+
+// questionOperator returns the JSONB operator written as op, which is ? if it is escaped as ??
+func questionOperator(op string) string {
+	if op == "??" {
+		return "?"
+	}
+	return op
+}
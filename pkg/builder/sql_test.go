@@ -63,6 +63,16 @@ func TestPlaceholderConverter(t *testing.T) {
 			after:  "SELECT a, b, 'a\\'b' FROM table_a WHERE id=$1",
 			mark:   "$",
 		},
+		{
+			before: `SELECT "a?" FROM table_a /* id=? */ WHERE id=? -- ?`,
+			after:  `SELECT "a?" FROM table_a /* id=? */ WHERE id=$1 -- ?`,
+			mark:   "$",
+		},
+		{
+			before: "SELECT $$a?$$ FROM table_a WHERE id=?",
+			after:  "SELECT $$a?$$ FROM table_a WHERE id=$1",
+			mark:   "$",
+		},
 	}
 	for _, kase := range convertCases {
 		t.Run(kase.before, func(t *testing.T) {
@@ -84,6 +94,9 @@ func TestBoundSQLConverter(t *testing.T) {
 	newSQL, err = ConvertToBoundSQL(placeholderConverterSQL, []interface{}{1, 2.1, sql2.Named("any", "3"), uint(4), "5", true})
 	assert.NoError(t, err)
 	assert.EqualValues(t, placeholderBoundSQL, newSQL)
+	newSQL, err = ConvertToBoundSQL("SELECT 'a?', \"b?\" FROM t WHERE c=? -- ?", []interface{}{1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT 'a?', \"b?\" FROM t WHERE c=1 -- ?", newSQL)
 	newSQL, err = ConvertToBoundSQL(placeholderConverterSQL, []interface{}{1, 2.1, "3", 4, "5"})
 	assert.Error(t, err)
 	assert.EqualValues(t, ErrNeedMoreArguments, err)
@@ -97,6 +110,30 @@ func TestBoundSQLConverter(t *testing.T) {
 	assert.Error(t, err)
 	assert.EqualValues(t, ErrNotSupportType, err)
 }
+func TestBuilder_DialectSyntax(t *testing.T) {
+	// JSONB operators are no placeholders, ?? stands for the ? operator
+	sql, args, err := Postgres().Select("id").From("t").
+		Where(Expr("data ?| array[?]", "a").And(Expr("data ?? ?", "b"), Expr("data ?& array[?]", "c"))).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM t WHERE (data ?| array[$1]) AND (data ? $2) AND (data ?& array[$3])", sql)
+	assert.EqualValues(t, []interface{}{"a", "b", "c"}, args)
+
+	sql, err = Postgres().Select("id").From("t").Where(Expr("data ?? ?", "b")).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id FROM t WHERE data ? 'b'", sql)
+
+	// brackets quote identifiers for SQL Server only
+	sql, args, err = MsSQL().Select("[a?]").From("t").Where(Eq{"b": 1}).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT [a?] FROM t WHERE b=@p1", sql)
+	assert.EqualValues(t, 1, len(args))
+
+	// MySQL's # comments
+	sql, err = MySQL().Select("a # ?\n").From("t").Where(Eq{"b": 1}).ToBoundSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT a # ?\n FROM t WHERE b=1", sql)
+}
+
 func TestSQL(t *testing.T) {
 	newSQL, args, err := ToSQL(In("a", 1, 2))
 	assert.NoError(t, err)
@@ -0,0 +1,269 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// structField is a field of a struct mapped to a column by its db tag:
+//
+//	`db:"column,omitempty,pk,readonly"`
+//
+// The column defaults to the snake case of the field name, `db:"-"` skips the field.
+// omitempty leaves zero values out of inserts and updates, pk marks the primary key
+// and readonly columns are only ever selected.
+type structField struct {
+	col       string
+	index     []int
+	omitEmpty bool
+	pk        bool
+	readonly  bool
+}
+
+var (
+	structFieldsCache sync.Map // map[reflect.Type][]structField
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// structFields returns the mapped fields of the struct type t including the ones of embedded structs
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var candidates []structField
+	collectStructFields(t, nil, []reflect.Type{t}, &candidates)
+
+	// like promoted fields, the least nested field of a column wins, while several
+	// ones of the same depth are ambiguous and left out
+	var (
+		depths = make(map[string]int)
+		counts = make(map[string]int)
+	)
+	for _, f := range candidates {
+		d, ok := depths[f.col]
+		switch {
+		case !ok || len(f.index) < d:
+			depths[f.col], counts[f.col] = len(f.index), 1
+		case len(f.index) == d:
+			counts[f.col]++
+		}
+	}
+	var fields []structField
+	for _, f := range candidates {
+		if len(f.index) == depths[f.col] && counts[f.col] == 1 {
+			fields = append(fields, f)
+		}
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// collectStructFields appends the fields of t and its embedded structs, nested at index, to fields.
+// path holds the struct types embedding t, which are not embedded again, e.g. by type Node struct{ *Node }.
+func collectStructFields(t reflect.Type, index []int, path []reflect.Type, fields *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && opts[0] == "" && ft.Kind() == reflect.Struct && !isValuer(f.Type) {
+			if !containsType(path, ft) {
+				collectStructFields(ft, fieldIndex, append(path[:len(path):len(path)], ft), fields)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		field := structField{col: opts[0], index: fieldIndex}
+		if field.col == "" {
+			field.col = snakeCase(f.Name)
+		}
+		for _, opt := range opts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "omitempty":
+				field.omitEmpty = true
+			case "pk":
+				field.pk = true
+			case "readonly":
+				field.readonly = true
+			}
+		}
+		*fields = append(*fields, field)
+	}
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, tt := range types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isValuer(t reflect.Type) bool {
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType)
+}
+
+// snakeCase converts a field name like UserID to user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// structValue returns the struct v points to or is
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, ErrNotStruct
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrNotStruct
+	}
+	return rv, nil
+}
+
+// fieldByIndex returns the field of rv at index or false if it is part of an embedded struct which is nil
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// fieldValue returns the value a field is written to the database with. Pointers are
+// dereferenced unless they implement driver.Valuer.
+func fieldValue(fv reflect.Value) interface{} {
+	if fv.Kind() == reflect.Ptr && !fv.Type().Implements(valuerType) {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	if !fv.Type().Implements(valuerType) && fv.CanAddr() && fv.Addr().Type().Implements(valuerType) {
+		return fv.Addr().Interface()
+	}
+	return fv.Interface()
+}
+
+// structEq maps the fields of v which are accepted by include to an Eq
+func structEq(v interface{}, include func(f structField) bool) (Eq, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	eq := Eq{}
+	for _, f := range structFields(rv.Type()) {
+		if !include(f) {
+			continue
+		}
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (f.omitEmpty && fv.IsZero()) {
+			continue
+		}
+		eq[f.col] = fieldValue(fv)
+	}
+	return eq, nil
+}
+
+// StructEq maps the fields of the struct v to the columns and values to insert,
+// i.e. all fields but the readonly ones and the omitempty ones with zero values.
+// It is what Insert does with a struct.
+func StructEq(v interface{}) (Eq, error) {
+	return structEq(v, func(f structField) bool {
+		return !f.readonly
+	})
+}
+
+// StructUpdates maps the fields of the struct v to the columns and values to update,
+// i.e. all fields but the primary key, the readonly ones and the omitempty ones with zero values
+func StructUpdates(v interface{}) (Eq, error) {
+	return structEq(v, func(f structField) bool {
+		return !f.readonly && !f.pk
+	})
+}
+
+// StructPK maps the primary key fields of the struct v to a condition identifying its row
+func StructPK(v interface{}) (Eq, error) {
+	eq, err := structEq(v, func(f structField) bool {
+		return f.pk
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(eq) == 0 {
+		return nil, ErrNoPrimaryKey
+	}
+	return eq, nil
+}
+
+// StructColumns returns the columns of the fields of v, which is a struct or a pointer to
+// or a slice of structs, in the order of the fields. It is meant to select them.
+func StructColumns(v interface{}) ([]string, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	fields := structFields(t)
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = f.col
+	}
+	return cols, nil
+}
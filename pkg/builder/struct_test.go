@@ -0,0 +1,153 @@
+package builder
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	sql2 "database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type audit struct {
+	CreatedAt time.Time `db:"created_at,readonly"`
+	UpdatedBy string    `db:",omitempty"`
+}
+
+type owner struct {
+	OwnerID int64
+	Name    string `db:"owner_name"`
+}
+
+type account struct {
+	ID       int64           `db:"id,pk"`
+	UserName string          `db:"user_name"`
+	Email    sql2.NullString `db:"email,omitempty"`
+	Balance  *float64        `db:"balance"`
+	Note     string          `db:"-"`
+	secret   string
+	audit
+	*owner
+	Name string
+}
+
+func TestStructEq(t *testing.T) {
+	balance := 1.5
+	a := account{ID: 1, UserName: "cat", Balance: &balance, Note: "n", secret: "s", Name: "top"}
+
+	eq, err := StructEq(a)
+	assert.NoError(t, err)
+	assert.EqualValues(t, Eq{"id": int64(1), "user_name": "cat", "balance": 1.5, "name": "top"}, eq)
+
+	a.Email = sql2.NullString{String: "cat@example.com", Valid: true}
+	a.UpdatedBy = "dog"
+	a.owner = &owner{OwnerID: 7, Name: "bird"}
+	a.Balance = nil
+	eq, err = StructEq(&a)
+	assert.NoError(t, err)
+	assert.EqualValues(t, Eq{"id": int64(1), "user_name": "cat", "email": a.Email, "balance": nil,
+		"updated_by": "dog", "owner_id": int64(7), "owner_name": "bird", "name": "top"}, eq)
+
+	eq, err = StructUpdates(&a)
+	assert.NoError(t, err)
+	assert.NotContains(t, eq, "id")
+	assert.NotContains(t, eq, "created_at")
+	assert.Len(t, eq, 7)
+
+	eq, err = StructPK(a)
+	assert.NoError(t, err)
+	assert.EqualValues(t, Eq{"id": int64(1)}, eq)
+
+	_, err = StructPK(owner{})
+	assert.EqualValues(t, ErrNoPrimaryKey, err)
+	_, err = StructEq(1)
+	assert.EqualValues(t, ErrNotStruct, err)
+	_, err = StructEq((*account)(nil))
+	assert.EqualValues(t, ErrNotStruct, err)
+}
+
+func TestStructColumns(t *testing.T) {
+	cols, err := StructColumns([]*account{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"id", "user_name", "email", "balance", "created_at", "updated_by", "owner_id", "owner_name", "name"}, cols)
+
+	_, err = StructColumns("account")
+	assert.EqualValues(t, ErrNotStruct, err)
+
+	// like ambiguous promoted fields, columns of embedded structs at the same depth are left out
+	type created struct {
+		By   string `db:"by"`
+		Note string
+	}
+	type updated struct {
+		By   string `db:"by"`
+		Note string
+	}
+	type change struct {
+		created
+		updated
+		Note string `db:"note"`
+	}
+	cols, err = StructColumns(change{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"note"}, cols)
+
+	// like promoted fields, embedding stops at a struct embedding itself
+	type node struct {
+		*node
+		Name string
+	}
+	cols, err = StructColumns(node{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"name"}, cols)
+	eq, err := StructEq(change{created{By: "a"}, updated{By: "b"}, "x"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, Eq{"note": "x"}, eq)
+
+	assert.EqualValues(t, "user_id", snakeCase("UserID"))
+	assert.EqualValues(t, "http_server", snakeCase("HTTPServer"))
+	assert.EqualValues(t, "created_at", snakeCase("CreatedAt"))
+}
+
+func TestBuilder_Struct(t *testing.T) {
+	a := account{ID: 1, UserName: "cat", Name: "top"}
+
+	sql, args, err := Postgres().Insert(a).Into("account").ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO account (balance,id,name,user_name) Values (null,$1,$2,$3)", sql)
+	assert.EqualValues(t, []interface{}{int64(1), "top", "cat"}, args)
+
+	updates, err := StructUpdates(a)
+	assert.NoError(t, err)
+	pk, err := StructPK(a)
+	assert.NoError(t, err)
+	sql, args, err = Postgres().Update(updates).From("account").Where(pk).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "UPDATE account SET balance=null,name=$1,user_name=$2 WHERE id=$3", sql)
+	assert.EqualValues(t, []interface{}{"top", "cat", int64(1)}, args)
+
+	cols, err := StructColumns(a)
+	assert.NoError(t, err)
+	sql, _, err = Select(cols...).From("account").Where(pk).ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT id,user_name,email,balance,created_at,updated_by,owner_id,owner_name,name FROM account WHERE id=?", sql)
+}
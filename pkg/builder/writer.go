@@ -56,3 +56,51 @@ func (w *BytesWriter) Append(args ...interface{}) {
 func (w *BytesWriter) Args() []interface{} {
 	return w.args
 }
+
+// dialectWriter carries the dialect specific settings of a statement along
+// to the conditions written through it
+type dialectWriter struct {
+	Writer
+	dialect SQLDialect
+	// quote quotes identifiers, see Builder.QuoteIdents
+	quote bool
+	// excluded references the values of a row which could not be inserted, see Excluded
+	excluded func(col string) string
+	// windows are the named windows inlined into OVER clauses, see Builder.Window
+	windows []namedWindow
+}
+
+// withDialect returns a dialectWriter writing to w which keeps the settings w might already have
+func withDialect(w Writer, dialect SQLDialect) *dialectWriter {
+	if dw, ok := w.(*dialectWriter); ok {
+		c := *dw
+		return &c
+	}
+	return &dialectWriter{Writer: w, dialect: dialect}
+}
+
+// quoteIdent quotes ident if the Writer has been set up to quote identifiers,
+// see Builder.QuoteIdents
+func quoteIdent(w Writer, ident string) string {
+	if dw, ok := w.(*dialectWriter); ok && dw.quote {
+		return quoteIdentExpr(dw.dialect, ident)
+	}
+	return ident
+}
+
+// writerDialect returns the dialect w has been set up with, if any
+func writerDialect(w Writer) SQLDialect {
+	if dw, ok := w.(*dialectWriter); ok {
+		return dw.dialect
+	}
+	return nil
+}
+
+// quoteOrder quotes the column of an order by term like column DESC if the Writer has been
+// set up to quote identifiers
+func quoteOrder(w Writer, term string) string {
+	if dw, ok := w.(*dialectWriter); ok && dw.quote {
+		return quoteOrderExpr(dw.dialect, term)
+	}
+	return term
+}
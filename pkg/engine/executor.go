@@ -0,0 +1,172 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bhojpur/sql/pkg/logcutter"
+)
+
+// Executor runs the work described by an engine spec
+type Executor interface {
+	// Execute runs spec within workdir and writes its log output to out.
+	// Execute must return once ctx is cancelled.
+	Execute(ctx context.Context, spec *Spec, workdir string, out io.Writer) error
+}
+
+// SQLExecutor executes engine specs using database/sql. The driver named
+// in the spec must be registered with database/sql.
+type SQLExecutor struct {
+	// DSNEnv are the environment variables of the server a DSN may reference as $VAR or ${VAR}.
+	// DSNs are sent by clients, so no other variables are expanded.
+	DSNEnv []string
+}
+
+var _ Executor = SQLExecutor{}
+
+// Execute implements Executor. Each statement and file is logged as a slice of its own.
+func (e SQLExecutor) Execute(ctx context.Context, spec *Spec, workdir string, out io.Writer) error {
+	fmt.Fprintf(out, "[connect|PHASE] connecting to %s database\n", spec.Driver)
+	dsn, err := e.expandDSN(spec.DSN)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open(spec.Driver, dsn)
+	if err != nil {
+		return fmt.Errorf("cannot open %s database: %w", spec.Driver, err)
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("cannot connect to %s database: %w", spec.Driver, err)
+	}
+	fmt.Fprintf(out, "connected to %s database\n", spec.Driver)
+
+	if len(spec.Statements) > 0 {
+		fmt.Fprintf(out, "[statements|PHASE] executing %d statement(s)\n", len(spec.Statements))
+	}
+	for i, stmt := range spec.Statements {
+		slice := fmt.Sprintf("statement.%d", i+1)
+		fmt.Fprintf(out, "[%s] executing statement %d of %d\n", slice, i+1, len(spec.Statements))
+		if err := execStatement(ctx, db, slice, stmt, out); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+
+	files, err := resolveFiles(workdir, spec.Files)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(out, "[files|PHASE] executing %d file(s)\n", len(files))
+	}
+	for _, fn := range files {
+		content, err := ioutil.ReadFile(filepath.Join(workdir, fn))
+		if err != nil {
+			return err
+		}
+		slice := logcutter.SliceName(fn)
+		fmt.Fprintf(out, "[%s] executing %s\n", slice, fn)
+		if err := execStatement(ctx, db, slice, string(content), out); err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+	return nil
+}
+
+// expandDSN expands the environment variables dsn references, which must be listed in DSNEnv
+func (e SQLExecutor) expandDSN(dsn string) (string, error) {
+	var err error
+	res := os.Expand(dsn, func(name string) string {
+		for _, allowed := range e.DSNEnv {
+			if name == allowed {
+				return os.Getenv(name)
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("dsn references environment variable %s which is not allowed", name)
+		}
+		return ""
+	})
+	if err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+// execStatement executes stmt and ends the log slice it belongs to
+func execStatement(ctx context.Context, db *sql.DB, slice, stmt string, out io.Writer) error {
+	res, err := db.ExecContext(ctx, stmt)
+	if err != nil {
+		fmt.Fprintf(out, "[%s|FAIL] %s\n", slice, strings.ReplaceAll(err.Error(), "\n", " "))
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		fmt.Fprintf(out, "[%s] %d row(s) affected\n", slice, n)
+	}
+	fmt.Fprintf(out, "[%s|DONE]\n", slice)
+	return nil
+}
+
+// resolveFiles expands the glob patterns relative to workdir and returns the
+// de-duplicated matches in lexical order.
+func resolveFiles(workdir string, patterns []string) ([]string, error) {
+	var (
+		res  []string
+		seen = make(map[string]struct{})
+	)
+	for _, p := range patterns {
+		if filepath.IsAbs(p) {
+			return nil, fmt.Errorf("file pattern %s must be relative", p)
+		}
+		matches, err := filepath.Glob(filepath.Join(workdir, p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %s: %w", p, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("file pattern %s matches nothing", p)
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(workdir, m)
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(rel, "..") {
+				return nil, fmt.Errorf("file pattern %s escapes the workspace", p)
+			}
+			if _, exists := seen[rel]; exists {
+				continue
+			}
+			seen[rel] = struct{}{}
+			res = append(res, rel)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
@@ -0,0 +1,172 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultSubscriberBufferSize is the number of engine updates buffered per subscriber
+// if the service configuration does not say otherwise
+const DefaultSubscriberBufferSize = 100
+
+// SlowSubscriberPolicy determines what happens to a subscriber whose buffer is full
+type SlowSubscriberPolicy string
+
+const (
+	// DropUpdates drops the updates a slow subscriber has no room for
+	DropUpdates SlowSubscriberPolicy = "drop"
+	// Disconnect ends the subscription of a slow subscriber
+	Disconnect SlowSubscriberPolicy = "disconnect"
+)
+
+// ParseSlowSubscriberPolicy parses a policy name as used on the command line
+func ParseSlowSubscriberPolicy(name string) (SlowSubscriberPolicy, error) {
+	switch p := SlowSubscriberPolicy(strings.ToLower(name)); p {
+	case DropUpdates, Disconnect:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown slow subscriber policy %q: must be %q or %q", name, DropUpdates, Disconnect)
+}
+
+// hub fans engine updates out to subscribers. Publishing never blocks: a subscriber
+// which falls behind is dealt with according to the hub's policy.
+type hub struct {
+	bufferSize int
+	policy     SlowSubscriberPolicy
+
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+
+	// closed is closed once the hub ends all subscriptions for good
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newHub(bufferSize int, policy SlowSubscriberPolicy) *hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultSubscriberBufferSize
+	}
+	if policy == "" {
+		policy = DropUpdates
+	}
+	return &hub{
+		bufferSize: bufferSize,
+		policy:     policy,
+		subs:       make(map[*subscription]struct{}),
+		closed:     make(chan struct{}),
+	}
+}
+
+// subscription receives all updates matching its filter
+type subscription struct {
+	filter  []*v1.FilterExpression
+	updates chan *v1.EngineStatus
+	dropped uint64
+
+	// disconnected is closed when the hub ended this subscription
+	disconnected chan struct{}
+	once         sync.Once
+}
+
+// Updates returns the channel the matching engine updates are delivered on
+func (s *subscription) Updates() <-chan *v1.EngineStatus {
+	return s.updates
+}
+
+// Disconnected is closed once the hub ended this subscription because it fell behind
+func (s *subscription) Disconnected() <-chan struct{} {
+	return s.disconnected
+}
+
+// Dropped returns the number of updates this subscriber missed
+func (s *subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Subscribe registers a subscriber for all updates matching filter. Callers must
+// unsubscribe once they are no longer interested in updates.
+func (h *hub) Subscribe(filter []*v1.FilterExpression) *subscription {
+	sub := &subscription{
+		filter:       filter,
+		updates:      make(chan *v1.EngineStatus, h.bufferSize),
+		disconnected: make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes a subscriber from the hub
+func (h *hub) Unsubscribe(sub *subscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
+
+// Close ends all subscriptions, present and future
+func (h *hub) Close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// Closed is closed once the hub has been closed
+func (h *hub) Closed() <-chan struct{} {
+	return h.closed
+}
+
+// Publish delivers an update to all subscribers whose filter matches it
+func (h *hub) Publish(status *v1.EngineStatus) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		if !filterexpr.MatchesFilter(status, sub.filter) {
+			continue
+		}
+		select {
+		case <-sub.disconnected:
+			continue
+		default:
+		}
+
+		select {
+		case sub.updates <- status:
+			continue
+		default:
+		}
+
+		switch h.policy {
+		case Disconnect:
+			sub.once.Do(func() { close(sub.disconnected) })
+			log.WithField("name", status.Name).Warn("subscriber is too slow - disconnecting")
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+			log.WithField("name", status.Name).Debug("subscriber is too slow - dropping engine update")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_Filter(t *testing.T) {
+	h := newHub(10, DropUpdates)
+	all := h.Subscribe(nil)
+	defer h.Unsubscribe(all)
+	owned := h.Subscribe([]*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "owner", Value: "alice"}}}})
+	defer h.Unsubscribe(owned)
+
+	h.Publish(&v1.EngineStatus{Name: "a.1", Metadata: &v1.EngineMetadata{Owner: "alice"}})
+	h.Publish(&v1.EngineStatus{Name: "b.1", Metadata: &v1.EngineMetadata{Owner: "bob"}})
+
+	assert.Len(t, all.Updates(), 2)
+	if assert.Len(t, owned.Updates(), 1) {
+		assert.EqualValues(t, "a.1", (<-owned.Updates()).Name)
+	}
+
+	h.Unsubscribe(owned)
+	h.Publish(&v1.EngineStatus{Name: "a.2", Metadata: &v1.EngineMetadata{Owner: "alice"}})
+	assert.Len(t, all.Updates(), 3)
+	assert.Len(t, owned.Updates(), 0)
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	tests := []struct {
+		Policy           SlowSubscriberPolicy
+		ExpectedDropped  uint64
+		ExpectedBuffered int
+		Disconnected     bool
+	}{
+		{Policy: DropUpdates, ExpectedDropped: 3, ExpectedBuffered: 2},
+		{Policy: Disconnect, ExpectedBuffered: 2, Disconnected: true},
+	}
+	for _, test := range tests {
+		t.Run(string(test.Policy), func(t *testing.T) {
+			h := newHub(2, test.Policy)
+			slow := h.Subscribe(nil)
+			defer h.Unsubscribe(slow)
+			fast := h.Subscribe(nil)
+			defer h.Unsubscribe(fast)
+
+			var received int
+			for i := 0; i < 5; i++ {
+				h.Publish(&v1.EngineStatus{Name: "engine.1"})
+				<-fast.Updates()
+				received++
+			}
+
+			assert.Equal(t, 5, received)
+			assert.Equal(t, test.ExpectedDropped, slow.Dropped())
+			assert.Len(t, slow.Updates(), test.ExpectedBuffered)
+			select {
+			case <-slow.Disconnected():
+				assert.True(t, test.Disconnected, "subscriber was disconnected")
+			default:
+				assert.False(t, test.Disconnected, "subscriber was not disconnected")
+			}
+		})
+	}
+}
+
+func TestParseSlowSubscriberPolicy(t *testing.T) {
+	p, err := ParseSlowSubscriberPolicy("Disconnect")
+	assert.NoError(t, err)
+	assert.Equal(t, Disconnect, p)
+	_, err = ParseSlowSubscriberPolicy("block")
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// logBuffer holds the complete log output of an engine in memory. Any number
+// of readers can follow the log while it is being written.
+type logBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	closed  bool
+	changed chan struct{}
+}
+
+func newLogBuffer() *logBuffer {
+	return &logBuffer{changed: make(chan struct{})}
+}
+
+// Write appends p to the log
+func (b *logBuffer) Write(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	b.data = append(b.data, p...)
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// Close marks the log as complete. Readers return io.EOF once they have
+// consumed all content.
+func (b *logBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	close(b.changed)
+	return nil
+}
+
+// Reader returns a reader which starts at the beginning of the log and blocks
+// until more content is written, the log is closed or ctx is done.
+func (b *logBuffer) Reader(ctx context.Context) io.Reader {
+	return &logReader{ctx: ctx, buf: b}
+}
+
+type logReader struct {
+	ctx    context.Context
+	buf    *logBuffer
+	offset int
+}
+
+func (r *logReader) Read(p []byte) (n int, err error) {
+	for {
+		r.buf.mu.Lock()
+		if r.offset < len(r.buf.data) {
+			n = copy(p, r.buf.data[r.offset:])
+			r.offset += n
+			r.buf.mu.Unlock()
+			return n, nil
+		}
+		closed, changed := r.buf.closed, r.buf.changed
+		r.buf.mu.Unlock()
+		if closed {
+			return 0, io.EOF
+		}
+
+		select {
+		case <-changed:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sync"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// registry keeps track of the engines started by this process
+type registry struct {
+	mu      sync.RWMutex
+	engines map[string]*runningEngine
+}
+
+type runningEngine struct {
+	status *v1.EngineStatus
+	logs   *logBuffer
+	cancel context.CancelFunc
+	// done is closed once the engine has reached its final status
+	done chan struct{}
+}
+
+func newRegistry() *registry {
+	return &registry{
+		engines: make(map[string]*runningEngine),
+	}
+}
+
+func (r *registry) add(status *v1.EngineStatus, logs *logBuffer, cancel context.CancelFunc) {
+	r.mu.Lock()
+	r.engines[status.Name] = &runningEngine{
+		status: proto.Clone(status).(*v1.EngineStatus),
+		logs:   logs,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	r.mu.Unlock()
+}
+
+// get returns a copy of the engine's status and its logs
+func (r *registry) get(name string) (status *v1.EngineStatus, logs *logBuffer, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.engines[name]
+	if !ok {
+		return nil, nil, false
+	}
+	return proto.Clone(e.status).(*v1.EngineStatus), e.logs, true
+}
+
+// finished returns a channel which is closed once the engine has reached its final status
+func (r *registry) finished(name string) (done <-chan struct{}, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.engines[name]
+	if !ok {
+		return nil, false
+	}
+	return e.done, true
+}
+
+// finish marks the engine as having reached its final status
+func (r *registry) finish(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.engines[name]; ok {
+		close(e.done)
+	}
+}
+
+// remove forgets a finished engine, releasing its logs
+func (r *registry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.engines, name)
+}
+
+// update modifies an engine's status and returns a copy of the result
+func (r *registry) update(name string, mod func(status *v1.EngineStatus)) (*v1.EngineStatus, bool) {
+	r.mu.Lock()
+	e, ok := r.engines[name]
+	if !ok {
+		r.mu.Unlock()
+		return nil, false
+	}
+	mod(e.status)
+	res := proto.Clone(e.status).(*v1.EngineStatus)
+	r.mu.Unlock()
+	return res, true
+}
+
+// stop cancels an engine. It returns false if the engine is unknown.
+func (r *registry) stop(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.engines[name]
+	if !ok {
+		return false
+	}
+	e.cancel()
+	return true
+}
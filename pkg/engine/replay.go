@@ -0,0 +1,91 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReplayOfAnnotation is the annotation which names the engine a replayed engine was started from
+const ReplayOfAnnotation = "replay-of"
+
+// StartFromPreviousEngine starts a new engine with the spec, sideload and metadata of a previous one.
+// This server does not integrate with a GitOps provider, hence the gitops_token is not used.
+func (srv *Service) StartFromPreviousEngine(ctx context.Context, req *v1.StartFromPreviousEngineRequest) (*v1.StartEngineResponse, error) {
+	if req.WaitUntil != nil {
+		if err := req.WaitUntil.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid wait_until: %v", err)
+		}
+	}
+
+	prev, err := srv.getEngine(ctx, req.PreviousEngine)
+	if err != nil {
+		return nil, err
+	}
+	if !prev.GetConditions().GetCanReplay() {
+		return nil, status.Errorf(codes.FailedPrecondition, "engine %s cannot be replayed", req.PreviousEngine)
+	}
+	stored, err := srv.Specs.Get(ctx, prev.Name)
+	if err == store.ErrNotFound {
+		return nil, status.Errorf(codes.FailedPrecondition, "the spec of engine %s is gone - it cannot be replayed", req.PreviousEngine)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot get spec of engine %s: %v", req.PreviousEngine, err)
+	}
+	spec, err := ParseSpec(stored.EngineYAML)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot replay engine %s: %v", req.PreviousEngine, err)
+	}
+
+	md := &v1.EngineMetadata{}
+	if prev.Metadata != nil {
+		md = proto.Clone(prev.Metadata).(*v1.EngineMetadata)
+	}
+	md.Created, md.Finished = nil, nil
+	annotations := make([]*v1.Annotation, 0, len(md.Annotations)+1)
+	for _, a := range md.Annotations {
+		if a.Key != ReplayOfAnnotation {
+			annotations = append(annotations, a)
+		}
+	}
+	md.Annotations = append(annotations, &v1.Annotation{Key: ReplayOfAnnotation, Value: prev.Name})
+
+	st, err := srv.start(ctx, replayBaseName(prev.Name), md, stored.EngineYAML, spec, sideloadReader(stored.Sideload), req.WaitUntil)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.StartEngineResponse{Status: st}, nil
+}
+
+// replayBaseName returns the name prefix of an engine, i.e. its name without the number
+func replayBaseName(name string) string {
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		return name[:idx]
+	}
+	return name
+}
@@ -0,0 +1,126 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestService_StartFromPreviousEngine(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	client := newTestClient(t, executor)
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{
+		Metadata: &v1.EngineMetadata{
+			Owner:       "dba",
+			Trigger:     v1.EngineTrigger_TRIGGER_PUSH,
+			Annotations: []*v1.Annotation{{Key: "ticket", Value: "OPS-42"}},
+		},
+		EngineYaml: []byte(testEngineYAML),
+		Sideload:   tarGz(t, map[string]string{"migrate.sql": "SELECT 1;"}),
+		NameSuffix: "nightly",
+	})
+	assert.NoError(t, err)
+	assert.True(t, resp.Status.Conditions.CanReplay)
+	waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+
+	replay, err := client.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: resp.Status.Name})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.EqualValues(t, "nightly.2", replay.Status.Name)
+	assert.EqualValues(t, "dba", replay.Status.Metadata.Owner)
+	assert.EqualValues(t, v1.EngineTrigger_TRIGGER_PUSH, replay.Status.Metadata.Trigger)
+	assert.Nil(t, replay.Status.Metadata.Finished)
+	assert.Equal(t, []string{"ticket=OPS-42", "replay-of=nightly.1"}, annotationStrings(replay.Status.Metadata.Annotations))
+	st := waitForPhase(t, client, replay.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.True(t, st.Conditions.Success)
+
+	// replaying a replay records its direct predecessor only
+	replay, err = client.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: replay.Status.Name})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ticket=OPS-42", "replay-of=nightly.2"}, annotationStrings(replay.Status.Metadata.Annotations))
+
+	_, err = client.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: "does-not-exist.1"})
+	assert.EqualValues(t, codes.NotFound, status.Code(err))
+}
+
+func TestService_StartFromPreviousEngineCannotReplay(t *testing.T) {
+	ctx := context.Background()
+	engines := store.NewInMemoryEngineStore()
+	specs := store.NewInMemoryEngineSpecStore()
+	srv := NewService(Config{WorkDir: t.TempDir()}, &fakeExecutor{}, engines, specs, store.NewInMemoryNumberGroup())
+
+	assert.NoError(t, engines.Store(ctx, &v1.EngineStatus{
+		Name:       "engine.1",
+		Phase:      v1.EnginePhase_PHASE_DONE,
+		Metadata:   &v1.EngineMetadata{},
+		Conditions: &v1.EngineConditions{},
+	}))
+	assert.NoError(t, specs.Store(ctx, "engine.1", &store.EngineSpec{EngineYAML: []byte(testEngineYAML)}))
+	_, err := srv.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: "engine.1"})
+	assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+
+	// can_replay without a spec is of no help either
+	assert.NoError(t, engines.Store(ctx, &v1.EngineStatus{
+		Name:       "engine.2",
+		Phase:      v1.EnginePhase_PHASE_DONE,
+		Metadata:   &v1.EngineMetadata{},
+		Conditions: &v1.EngineConditions{CanReplay: true},
+	}))
+	_, err = srv.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: "engine.2"})
+	assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestService_StartFromPreviousEngineLargeSideload(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	sideload := tarGz(t, map[string]string{"migrate.sql": "SELECT 1;"})
+	client := newTestClientWithConfig(t, Config{MaxStoredSideload: int64(len(sideload) - 1)}, executor)
+	ctx := context.Background()
+
+	// the engine runs, but its sideload is not kept to run it again
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML), Sideload: sideload})
+	assert.NoError(t, err)
+	assert.False(t, resp.Status.Conditions.CanReplay)
+	st := waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.True(t, st.Conditions.Success)
+
+	_, err = client.StartFromPreviousEngine(ctx, &v1.StartFromPreviousEngineRequest{PreviousEngine: resp.Status.Name})
+	assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func annotationStrings(annotations []*v1.Annotation) []string {
+	res := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		res = append(res, a.Key+"="+a.Value)
+	}
+	return res
+}
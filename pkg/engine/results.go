@@ -0,0 +1,82 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/logcutter"
+	log "github.com/sirupsen/logrus"
+)
+
+// resultWriter watches the log output of an engine for results
+type resultWriter struct {
+	name    string
+	cutter  *logcutter.Cutter
+	buf     []byte
+	publish func(*v1.EngineResult)
+}
+
+func newResultWriter(name string, publish func(*v1.EngineResult)) *resultWriter {
+	return &resultWriter{
+		name:    name,
+		cutter:  logcutter.New(),
+		publish: publish,
+	}
+}
+
+// Write implements io.Writer
+func (w *resultWriter) Write(p []byte) (n int, err error) {
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		w.line(string(w.buf[:idx]))
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Close processes the last line if it was not terminated by a line break
+func (w *resultWriter) Close() error {
+	if len(w.buf) > 0 {
+		w.line(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *resultWriter) line(line string) {
+	for _, evt := range w.cutter.Line(line) {
+		if evt.Type != v1.LogSliceType_SLICE_RESULT {
+			continue
+		}
+		res, err := logcutter.ParseResult(evt)
+		if err != nil {
+			log.WithError(err).WithField("name", w.name).Warn("engine produced an invalid result")
+			continue
+		}
+		w.publish(res)
+	}
+}
@@ -0,0 +1,737 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/bhojpur/sql/pkg/logcutter"
+	"github.com/bhojpur/sql/pkg/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Config configures the engine service
+type Config struct {
+	// SpecDir is the directory the engine_path of StartEngine requests is resolved against.
+	// If empty, StartEngine requests must carry their engine YAML.
+	SpecDir string
+	// WorkDir is the directory in which engine workspaces are created.
+	// If empty, the system's temporary directory is used.
+	WorkDir string
+	// SubscriberBufferSize is the number of engine updates buffered for each
+	// Subscribe and Listen call. Defaults to DefaultSubscriberBufferSize.
+	SubscriberBufferSize int
+	// SlowSubscriberPolicy determines what happens to subscribers which cannot
+	// keep up with engine updates. Defaults to DropUpdates.
+	SlowSubscriberPolicy SlowSubscriberPolicy
+	// MaxUploadSize limits the size of the gzipped application tar StartLocalEngine
+	// accepts. Defaults to DefaultMaxUploadSize.
+	MaxUploadSize int64
+	// MaxWorkspaceSize limits the size of the extracted content of an engine workspace.
+	// Defaults to DefaultMaxWorkspaceSize.
+	MaxWorkspaceSize int64
+	// LogRetention is how long the logs of a finished engine are kept in memory to be listened to.
+	// Afterwards only the stored status of the engine is available. Defaults to DefaultLogRetention.
+	LogRetention time.Duration
+	// MaxStoredSideload limits the size of the sideload stored along with the spec of an engine
+	// to replay it or to resume it after a restart. Engines with larger sideloads can neither be
+	// replayed nor resumed. Defaults to DefaultMaxStoredSideload.
+	MaxStoredSideload int64
+}
+
+const (
+	// DefaultLogRetention is the default time the logs of a finished engine are kept in memory
+	DefaultLogRetention = 15 * time.Minute
+	// DefaultMaxStoredSideload is the default size limit of a sideload stored to replay an engine
+	DefaultMaxStoredSideload = 8 << 20
+)
+
+// Service implements the Bhojpur SQL engine API
+type Service struct {
+	Config   Config
+	Executor Executor
+	Engines  store.Engines
+	Specs    store.EngineSpecs
+	Numbers  store.NumberGroup
+
+	engines *registry
+	hub     *hub
+
+	v1.UnimplementedSqlServiceServer
+}
+
+// NewService creates a new engine service
+func NewService(cfg Config, executor Executor, engines store.Engines, specs store.EngineSpecs, numbers store.NumberGroup) *Service {
+	if cfg.MaxUploadSize <= 0 {
+		cfg.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if cfg.MaxWorkspaceSize <= 0 {
+		cfg.MaxWorkspaceSize = DefaultMaxWorkspaceSize
+	}
+	if cfg.LogRetention <= 0 {
+		cfg.LogRetention = DefaultLogRetention
+	}
+	if cfg.MaxStoredSideload <= 0 {
+		cfg.MaxStoredSideload = DefaultMaxStoredSideload
+	}
+	return &Service{
+		Config:   cfg,
+		Executor: executor,
+		Engines:  engines,
+		Specs:    specs,
+		Numbers:  numbers,
+		engines:  newRegistry(),
+		hub:      newHub(cfg.SubscriberBufferSize, cfg.SlowSubscriberPolicy),
+	}
+}
+
+// Recover resumes engines which were waiting to start when a previous server process
+// ended, and marks all other engines which were still in progress as failed.
+// Recover must be called before the service is used.
+func (srv *Service) Recover(ctx context.Context) error {
+	const pageSize = 100
+	notDone := []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{
+		Field:  "phase",
+		Value:  filterexpr.PhaseValue(v1.EnginePhase_PHASE_DONE),
+		Negate: true,
+	}}}}
+	var interrupted []*v1.EngineStatus
+	for start := 0; ; start += pageSize {
+		slice, total, err := srv.Engines.Find(ctx, notDone, nil, start, pageSize)
+		if err != nil {
+			return err
+		}
+		interrupted = append(interrupted, slice...)
+		if start+pageSize >= total {
+			break
+		}
+	}
+
+	for _, st := range interrupted {
+		// statuses stored by other means may lack them, yet running and failing engines need them
+		if st.Conditions == nil {
+			st.Conditions = &v1.EngineConditions{}
+		}
+		if st.Metadata == nil {
+			st.Metadata = &v1.EngineMetadata{}
+		}
+		if st.Phase == v1.EnginePhase_PHASE_WAITING {
+			err := srv.resume(ctx, st)
+			if err == nil {
+				log.WithField("name", st.Name).WithField("waitUntil", st.Conditions.WaitUntil.AsTime()).Info("resumed waiting engine")
+				continue
+			}
+			log.WithError(err).WithField("name", st.Name).Warn("cannot resume waiting engine")
+		}
+
+		st.Phase = v1.EnginePhase_PHASE_DONE
+		st.Conditions.Success = false
+		st.Conditions.FailureCount++
+		st.Details = "engine was interrupted by a server restart"
+		st.Metadata.Finished = timestamppb.Now()
+		if err := srv.Engines.Store(ctx, st); err != nil {
+			return err
+		}
+		log.WithField("name", st.Name).Warn("marked interrupted engine as failed")
+	}
+	return nil
+}
+
+// resume launches a waiting engine from its stored spec
+func (srv *Service) resume(ctx context.Context, st *v1.EngineStatus) error {
+	stored, err := srv.Specs.Get(ctx, st.Name)
+	if err != nil {
+		return fmt.Errorf("cannot get engine spec: %w", err)
+	}
+	spec, err := ParseSpec(stored.EngineYAML)
+	if err != nil {
+		return err
+	}
+	workdir, err := srv.prepareWorkspace(st.Name, sideloadReader(stored.Sideload))
+	if err != nil {
+		return err
+	}
+	srv.launch(st, spec, workdir)
+	return nil
+}
+
+// StartEngine starts a new engine based on its specification
+func (srv *Service) StartEngine(ctx context.Context, req *v1.StartEngineRequest) (*v1.StartEngineResponse, error) {
+	if req.WaitUntil != nil {
+		if err := req.WaitUntil.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid wait_until: %v", err)
+		}
+	}
+
+	engineYAML := req.EngineYaml
+	if len(engineYAML) == 0 {
+		var err error
+		engineYAML, err = srv.loadEngineYAML(req.EnginePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	spec, err := ParseSpec(engineYAML)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	md := &v1.EngineMetadata{}
+	if req.Metadata != nil {
+		md = proto.Clone(req.Metadata).(*v1.EngineMetadata)
+	}
+	if md.EngineSpecName == "" && req.EnginePath != "" {
+		md.EngineSpecName = strings.TrimSuffix(filepath.Base(req.EnginePath), filepath.Ext(req.EnginePath))
+	}
+
+	st, err := srv.start(ctx, engineBaseName(md, req.NameSuffix), md, engineYAML, spec, sideloadReader(req.Sideload), req.WaitUntil)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.StartEngineResponse{Status: st}, nil
+}
+
+func (srv *Service) loadEngineYAML(path string) ([]byte, error) {
+	if path == "" {
+		return nil, status.Error(codes.InvalidArgument, "either engine_yaml or engine_path is required")
+	}
+	if srv.Config.SpecDir == "" {
+		return nil, status.Error(codes.FailedPrecondition, "this server has no spec directory - engine_yaml is required")
+	}
+	fn := filepath.Join(srv.Config.SpecDir, filepath.Clean("/"+path))
+	res, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return nil, status.Errorf(codes.NotFound, "engine spec %s does not exist", path)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot read engine spec %s: %v", path, err)
+	}
+	return res, nil
+}
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// engineBaseName produces the name prefix for an engine, e.g. migrate-nightly
+func engineBaseName(md *v1.EngineMetadata, suffix string) string {
+	base := md.EngineSpecName
+	if base == "" && md.Repository != nil {
+		base = md.Repository.Repo
+	}
+	if suffix != "" {
+		base += "-" + suffix
+	}
+	base = strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if base == "" {
+		base = "engine"
+	}
+	return base
+}
+
+// start registers a new engine named after base and runs it in the background -
+// right away, or once waitUntil has passed
+func (srv *Service) start(ctx context.Context, base string, md *v1.EngineMetadata, engineYAML []byte, spec *Spec, sideload io.ReadSeeker, waitUntil *timestamppb.Timestamp) (*v1.EngineStatus, error) {
+	nr, err := srv.Numbers.Next(ctx, base)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot number engine: %v", err)
+	}
+	name := fmt.Sprintf("%s.%d", base, nr)
+
+	workdir, err := srv.prepareWorkspace(name, sideload)
+	if err != nil {
+		return nil, err
+	}
+	stored, canReplay, err := srv.storedSideload(sideload)
+	if err != nil {
+		os.RemoveAll(workdir)
+		return nil, status.Errorf(codes.Internal, "cannot read sideload: %v", err)
+	}
+	if canReplay {
+		err = srv.Specs.Store(ctx, name, &store.EngineSpec{EngineYAML: engineYAML, Sideload: stored})
+		if err != nil {
+			os.RemoveAll(workdir)
+			return nil, status.Errorf(codes.Internal, "cannot store engine spec: %v", err)
+		}
+	}
+
+	if md.Created == nil {
+		md.Created = timestamppb.Now()
+	}
+	st := &v1.EngineStatus{
+		Name:     name,
+		Metadata: md,
+		Phase:    v1.EnginePhase_PHASE_PREPARING,
+		Conditions: &v1.EngineConditions{
+			// we have stored everything needed to run the engine again, unless its sideload is too large
+			CanReplay: canReplay,
+		},
+	}
+	if waitUntil != nil && waitUntil.AsTime().After(time.Now()) {
+		st.Phase = v1.EnginePhase_PHASE_WAITING
+		st.Conditions.WaitUntil = waitUntil
+	}
+	if err := srv.Engines.Store(ctx, st); err != nil {
+		os.RemoveAll(workdir)
+		return nil, status.Errorf(codes.Internal, "cannot store engine: %v", err)
+	}
+
+	srv.launch(st, spec, workdir)
+	return st, nil
+}
+
+// storedSideload reads the sideload once more to store it along with the spec. If it exceeds
+// MaxStoredSideload, no spec is stored and the engine cannot be run again.
+func (srv *Service) storedSideload(sideload io.ReadSeeker) (stored []byte, canReplay bool, err error) {
+	if sideload == nil {
+		return nil, true, nil
+	}
+	size, err := sideload.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, false, err
+	}
+	if size > srv.Config.MaxStoredSideload {
+		return nil, false, nil
+	}
+	if _, err := sideload.Seek(0, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+	stored, err = ioutil.ReadAll(sideload)
+	if err != nil {
+		return nil, false, err
+	}
+	return stored, true, nil
+}
+
+// sideloadReader returns a reader of a sideload, nil if there is none
+func sideloadReader(sideload []byte) io.ReadSeeker {
+	if len(sideload) == 0 {
+		return nil
+	}
+	return bytes.NewReader(sideload)
+}
+
+// prepareWorkspace creates the workspace of an engine and populates it with the sideload, if there is one
+func (srv *Service) prepareWorkspace(name string, sideload io.Reader) (workdir string, err error) {
+	workdir, err = ioutil.TempDir(srv.Config.WorkDir, name+"-")
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "cannot create workspace: %v", err)
+	}
+	if sideload == nil {
+		return workdir, nil
+	}
+	err = extractTarGz(sideload, workdir, srv.Config.MaxWorkspaceSize)
+	if errors.Is(err, errWorkspaceTooLarge) {
+		os.RemoveAll(workdir)
+		return "", status.Errorf(codes.ResourceExhausted, "cannot extract sideload: %v", err)
+	}
+	if err != nil {
+		os.RemoveAll(workdir)
+		return "", status.Errorf(codes.InvalidArgument, "cannot extract sideload: %v", err)
+	}
+	return workdir, nil
+}
+
+// launch registers an engine with this process and runs it in the background
+func (srv *Service) launch(st *v1.EngineStatus, spec *Spec, workdir string) {
+	logs := newLogBuffer()
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.engines.add(st, logs, cancel)
+	srv.hub.Publish(proto.Clone(st).(*v1.EngineStatus))
+
+	go srv.run(ctx, st.Name, spec, workdir, logs, st.Conditions.GetWaitUntil())
+}
+
+// run executes an engine once waitUntil has passed and keeps its status up to date
+func (srv *Service) run(ctx context.Context, name string, spec *Spec, workdir string, logs *logBuffer, waitUntil *timestamppb.Timestamp) {
+	log := log.WithField("name", name)
+	defer os.RemoveAll(workdir)
+
+	if waitUntil != nil && !wait(ctx, waitUntil.AsTime()) {
+		logs.Close()
+		srv.update(name, func(s *v1.EngineStatus) {
+			s.Phase = v1.EnginePhase_PHASE_DONE
+			s.Metadata.Finished = timestamppb.Now()
+			s.Details = "engine was stopped while waiting"
+		})
+		srv.finish(name)
+		log.Info("engine was stopped while waiting")
+		return
+	}
+
+	srv.update(name, func(s *v1.EngineStatus) {
+		s.Phase = v1.EnginePhase_PHASE_RUNNING
+		s.Conditions.DidExecute = true
+	})
+	log.Info("engine started")
+
+	results := newResultWriter(name, func(res *v1.EngineResult) {
+		srv.update(name, func(s *v1.EngineStatus) {
+			s.Results = append(s.Results, res)
+		})
+	})
+	err := srv.Executor.Execute(ctx, spec, workdir, io.MultiWriter(logs, results))
+	results.Close()
+	if ctx.Err() != nil {
+		err = fmt.Errorf("engine was stopped")
+	}
+
+	srv.update(name, func(s *v1.EngineStatus) {
+		s.Phase = v1.EnginePhase_PHASE_CLEANUP
+	})
+	if err != nil {
+		fmt.Fprintf(logs, "engine failed: %v\n", err)
+	} else {
+		fmt.Fprintln(logs, "engine done")
+	}
+	logs.Close()
+
+	srv.update(name, func(s *v1.EngineStatus) {
+		s.Phase = v1.EnginePhase_PHASE_DONE
+		s.Metadata.Finished = timestamppb.Now()
+		s.Conditions.Success = err == nil
+		if err != nil {
+			s.Conditions.FailureCount++
+			s.Details = err.Error()
+		}
+	})
+	srv.finish(name)
+	log.WithError(err).Info("engine done")
+}
+
+// finish marks an engine as having reached its final status, which has been stored,
+// and forgets it once its logs have been retained long enough
+func (srv *Service) finish(name string) {
+	srv.engines.finish(name)
+	time.AfterFunc(srv.Config.LogRetention, func() {
+		srv.engines.remove(name)
+	})
+}
+
+// wait blocks until t has passed or ctx is done. It returns false if ctx is done first.
+func wait(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// update modifies the status of a running engine, persists it and notifies all listeners
+func (srv *Service) update(name string, mod func(status *v1.EngineStatus)) {
+	st, ok := srv.engines.update(name, mod)
+	if !ok {
+		return
+	}
+	if err := srv.Engines.Store(context.Background(), st); err != nil {
+		log.WithError(err).WithField("name", name).Error("cannot store engine status")
+	}
+	srv.hub.Publish(st)
+}
+
+// ListEngines searches for engines known to this server
+func (srv *Service) ListEngines(ctx context.Context, req *v1.ListEnginesRequest) (*v1.ListEnginesResponse, error) {
+	if req.Start < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "start and limit must not be negative")
+	}
+	if err := validateFilter(req.Filter); err != nil {
+		return nil, err
+	}
+	for _, o := range req.Order {
+		if !filterexpr.IsValidOrderField(o.Field) {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot order by %q", o.Field)
+		}
+	}
+
+	slice, total, err := srv.Engines.Find(ctx, req.Filter, req.Order, int(req.Start), int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list engines: %v", err)
+	}
+	return &v1.ListEnginesResponse{Total: int32(total), Result: slice}, nil
+}
+
+// validateFilter ensures all filter terms refer to fields we can filter by
+func validateFilter(filter []*v1.FilterExpression) error {
+	for _, expr := range filter {
+		for _, term := range expr.Terms {
+			if !filterexpr.IsValidField(term.Field) {
+				return status.Errorf(codes.InvalidArgument, "cannot filter by %q", term.Field)
+			}
+			if term.Field == "success" && term.Operation == v1.FilterOp_OP_EXISTS {
+				// stores without a notion of absent conditions would match every engine
+				return status.Error(codes.InvalidArgument, "cannot filter by the existence of success")
+			}
+		}
+	}
+	return nil
+}
+
+// GetEngine retrieves details of a single engine
+func (srv *Service) GetEngine(ctx context.Context, req *v1.GetEngineRequest) (*v1.GetEngineResponse, error) {
+	st, err := srv.getEngine(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.GetEngineResponse{Result: st}, nil
+}
+
+// getEngine retrieves the status of an engine from the store
+func (srv *Service) getEngine(ctx context.Context, name string) (*v1.EngineStatus, error) {
+	st, err := srv.Engines.Get(ctx, name)
+	if err == store.ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "engine %s not found", name)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot get engine %s: %v", name, err)
+	}
+	return st, nil
+}
+
+// StopEngine stops a currently running engine
+func (srv *Service) StopEngine(ctx context.Context, req *v1.StopEngineRequest) (*v1.StopEngineResponse, error) {
+	st, _, ok := srv.engines.get(req.Name)
+	if !ok {
+		var err error
+		st, err = srv.getEngine(ctx, req.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if st.Phase == v1.EnginePhase_PHASE_DONE {
+		return nil, status.Errorf(codes.FailedPrecondition, "engine %s is already done", req.Name)
+	}
+	if !srv.engines.stop(req.Name) {
+		return nil, status.Errorf(codes.FailedPrecondition, "engine %s is not run by this server", req.Name)
+	}
+	return &v1.StopEngineResponse{}, nil
+}
+
+// Subscribe streams all engine updates matching the request's filter
+func (srv *Service) Subscribe(req *v1.SubscribeRequest, resp v1.SqlService_SubscribeServer) error {
+	if err := validateFilter(req.Filter); err != nil {
+		return err
+	}
+
+	sub := srv.hub.Subscribe(req.Filter)
+	defer srv.hub.Unsubscribe(sub)
+	for {
+		select {
+		case <-resp.Context().Done():
+			return nil
+		case <-srv.hub.Closed():
+			return errShuttingDown
+		case <-sub.Disconnected():
+			return errTooSlow
+		case u := <-sub.Updates():
+			if err := resp.Send(&v1.SubscribeResponse{Result: u}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// errTooSlow ends streams whose client cannot keep up with engine updates
+var errTooSlow = status.Error(codes.ResourceExhausted, "client is too slow to receive engine updates")
+
+// errShuttingDown ends streams when the server shuts down
+var errShuttingDown = status.Error(codes.Unavailable, "server is shutting down")
+
+// Shutdown ends the streams of all subscribers and listeners, which would otherwise keep a
+// graceful stop of the gRPC server waiting for them. Engines keep running.
+func (srv *Service) Shutdown() {
+	srv.hub.Close()
+}
+
+// Listen streams the updates and log output of a single engine until it is done
+func (srv *Service) Listen(req *v1.ListenRequest, resp v1.SqlService_ListenServer) error {
+	if !req.Updates && req.Logs == v1.ListenRequestLogs_LOGS_DISABLED {
+		return status.Error(codes.InvalidArgument, "must listen to updates, logs or both")
+	}
+
+	var (
+		updates      <-chan *v1.EngineStatus
+		disconnected <-chan struct{}
+	)
+	if req.Updates {
+		sub := srv.hub.Subscribe([]*v1.FilterExpression{{Terms: []*v1.FilterTerm{{
+			Field:     "name",
+			Value:     req.Name,
+			Operation: v1.FilterOp_OP_EQUALS,
+		}}}})
+		defer srv.hub.Unsubscribe(sub)
+		updates, disconnected = sub.Updates(), sub.Disconnected()
+	}
+	st, logs, ok := srv.engines.get(req.Name)
+	finished, _ := srv.engines.finished(req.Name)
+	if !ok {
+		// the engine was not started by this process - all we have is its history
+		st, err := srv.getEngine(resp.Context(), req.Name)
+		if err != nil {
+			return err
+		}
+		if !req.Updates {
+			return status.Errorf(codes.NotFound, "logs of engine %s are not available", req.Name)
+		}
+		return resp.Send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: st}})
+	}
+
+	ctx, cancel := context.WithCancel(resp.Context())
+	defer cancel()
+
+	var mu sync.Mutex
+	send := func(r *v1.ListenResponse) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return resp.Send(r)
+	}
+
+	done := st.Phase == v1.EnginePhase_PHASE_DONE
+	if req.Updates {
+		if err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: st}}); err != nil {
+			return err
+		}
+		if done && req.Logs == v1.ListenRequestLogs_LOGS_DISABLED {
+			return nil
+		}
+	}
+
+	var logsDone chan error
+	if req.Logs != v1.ListenRequestLogs_LOGS_DISABLED {
+		logsDone = make(chan error, 1)
+		go func() {
+			logsDone <- forwardLogs(logs.Reader(ctx), req.Logs, func(evt *v1.LogSliceEvent) error {
+				return send(&v1.ListenResponse{Content: &v1.ListenResponse_Slice{Slice: evt}})
+			})
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-srv.hub.Closed():
+			return errShuttingDown
+		case <-disconnected:
+			return errTooSlow
+		case u := <-updates:
+			if done {
+				continue
+			}
+			if err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: u}}); err != nil {
+				return err
+			}
+			if u.Phase == v1.EnginePhase_PHASE_DONE {
+				done = true
+				if logsDone == nil {
+					return nil
+				}
+			}
+		case <-finished:
+			// updates may have been dropped for a slow client - make sure it sees the final status
+			finished = nil
+			if !req.Updates {
+				continue
+			}
+			if !done {
+				st, _, ok := srv.engines.get(req.Name)
+				if !ok {
+					// the engine has been forgotten meanwhile, its final status is stored
+					var err error
+					if st, err = srv.getEngine(ctx, req.Name); err != nil {
+						return err
+					}
+				}
+				if err := send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: st}}); err != nil {
+					return err
+				}
+				done = true
+			}
+			if logsDone == nil {
+				return nil
+			}
+		case err := <-logsDone:
+			if err != nil {
+				return err
+			}
+			if !req.Updates || done {
+				return nil
+			}
+			logsDone = nil
+		}
+	}
+}
+
+// forwardLogs reads the log until EOF and passes it on in the requested mode
+func forwardLogs(r io.Reader, mode v1.ListenRequestLogs, send func(*v1.LogSliceEvent) error) error {
+	switch mode {
+	case v1.ListenRequestLogs_LOGS_RAW:
+		return logcutter.Slice(r, send)
+	case v1.ListenRequestLogs_LOGS_HTML:
+		return logcutter.Slice(r, func(evt *v1.LogSliceEvent) error {
+			evt.Payload = logcutter.ToHTML(evt.Payload)
+			return send(evt)
+		})
+	default:
+		return forwardUnsliced(r, send)
+	}
+}
+
+// forwardUnsliced reads the log until EOF and passes it on as content of the default slice
+func forwardUnsliced(r io.Reader, send func(*v1.LogSliceEvent) error) error {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			serr := send(&v1.LogSliceEvent{
+				Name:    logcutter.DefaultSlice,
+				Type:    v1.LogSliceType_SLICE_CONTENT,
+				Payload: string(buf[:n]),
+			})
+			if serr != nil {
+				return serr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
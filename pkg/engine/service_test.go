@@ -0,0 +1,476 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const testEngineYAML = `
+driver: postgres
+dsn: postgres://localhost/test
+statements:
+- SELECT 1
+`
+
+// fakeExecutor writes a slice of log output and a result, and blocks until it is released
+type fakeExecutor struct {
+	release chan struct{}
+	err     error
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, spec *Spec, workdir string, out io.Writer) error {
+	fmt.Fprintln(out, "[greet] \x1b[32mhello\x1b[0m from the engine")
+	fmt.Fprintln(out, "[greet|DONE]")
+	fmt.Fprintln(out, "[rows|RESULT] 42 rows were migrated")
+	select {
+	case <-f.release:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newTestClient(t *testing.T, executor Executor) v1.SqlServiceClient {
+	return newTestClientWithConfig(t, Config{}, executor)
+}
+
+func newTestClientWithConfig(t *testing.T, cfg Config, executor Executor) v1.SqlServiceClient {
+	_, client := newTestService(t, cfg, executor)
+	return client
+}
+
+func newTestService(t *testing.T, cfg Config, executor Executor) (*Service, v1.SqlServiceClient) {
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = t.TempDir()
+	}
+	srv := NewService(cfg, executor, store.NewInMemoryEngineStore(), store.NewInMemoryEngineSpecStore(), store.NewInMemoryNumberGroup())
+	l := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	v1.RegisterSqlServiceServer(s, srv)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, v1.NewSqlServiceClient(conn)
+}
+
+func waitForPhase(t *testing.T, client v1.SqlServiceClient, name string, phase v1.EnginePhase) *v1.EngineStatus {
+	for i := 0; i < 100; i++ {
+		resp, err := client.GetEngine(context.Background(), &v1.GetEngineRequest{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Result.Phase == phase {
+			return resp.Result
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("engine %s never reached %s", name, phase)
+	return nil
+}
+
+func TestService_StartEngine(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	client := newTestClient(t, executor)
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{
+		Metadata:   &v1.EngineMetadata{Owner: "tester", EngineSpecName: "Migrate"},
+		EngineYaml: []byte(testEngineYAML),
+		NameSuffix: "nightly",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "migrate-nightly.1", resp.Status.Name)
+	assert.NotNil(t, resp.Status.Metadata.Created)
+
+	waitForPhase(t, client, "migrate-nightly.1", v1.EnginePhase_PHASE_RUNNING)
+	close(executor.release)
+	st := waitForPhase(t, client, "migrate-nightly.1", v1.EnginePhase_PHASE_DONE)
+	assert.True(t, st.Conditions.Success)
+	assert.True(t, st.Conditions.DidExecute)
+	assert.NotNil(t, st.Metadata.Finished)
+	assert.Equal(t, []string{"rows: 42 rows were migrated"}, resultStrings(st.Results))
+
+	_, err = client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte("driver: postgres")})
+	assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.StartEngine(ctx, &v1.StartEngineRequest{EnginePath: "migrate.yaml"})
+	assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func resultStrings(results []*v1.EngineResult) []string {
+	res := make([]string, len(results))
+	for i, r := range results {
+		res[i] = fmt.Sprintf("%s: %s %s", r.Type, r.Payload, r.Description)
+	}
+	return res
+}
+
+func TestService_ListEngines(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	client := newTestClient(t, executor)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML)})
+		assert.NoError(t, err)
+	}
+	resp, err := client.ListEngines(ctx, &v1.ListEnginesRequest{Start: 1, Limit: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, resp.Total)
+	if assert.Len(t, resp.Result, 2) {
+		assert.EqualValues(t, "engine.2", resp.Result[0].Name)
+		assert.EqualValues(t, "engine.3", resp.Result[1].Name)
+	}
+	resp, err = client.ListEngines(ctx, &v1.ListEnginesRequest{Start: 10})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Result, 0)
+	resp, err = client.ListEngines(ctx, &v1.ListEnginesRequest{
+		Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "name", Value: "engine.4"}}}},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, resp.Total)
+	_, err = client.ListEngines(ctx, &v1.ListEnginesRequest{
+		Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "1=1; --", Value: "x"}}}},
+	})
+	assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ListEngines(ctx, &v1.ListEnginesRequest{
+		Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "success", Operation: v1.FilterOp_OP_EXISTS}}}},
+	})
+	assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_Recover(t *testing.T) {
+	ctx := context.Background()
+	engines := store.NewInMemoryEngineStore()
+	assert.NoError(t, engines.Store(ctx, &v1.EngineStatus{
+		Name:       "engine.1",
+		Phase:      v1.EnginePhase_PHASE_RUNNING,
+		Metadata:   &v1.EngineMetadata{},
+		Conditions: &v1.EngineConditions{},
+	}))
+	// engine.4 lacks conditions and metadata altogether
+	assert.NoError(t, engines.Store(ctx, &v1.EngineStatus{
+		Name:  "engine.4",
+		Phase: v1.EnginePhase_PHASE_RUNNING,
+	}))
+	// engine.2 was waiting and is due by now, engine.3 was waiting but its spec is lost
+	for _, name := range []string{"engine.2", "engine.3"} {
+		assert.NoError(t, engines.Store(ctx, &v1.EngineStatus{
+			Name:       name,
+			Phase:      v1.EnginePhase_PHASE_WAITING,
+			Metadata:   &v1.EngineMetadata{},
+			Conditions: &v1.EngineConditions{WaitUntil: timestamppb.New(time.Now().Add(-time.Minute))},
+		}))
+	}
+	specs := store.NewInMemoryEngineSpecStore()
+	assert.NoError(t, specs.Store(ctx, "engine.2", &store.EngineSpec{EngineYAML: []byte(testEngineYAML)}))
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	srv := NewService(Config{WorkDir: t.TempDir()}, executor, engines, specs, store.NewInMemoryNumberGroup())
+	assert.NoError(t, srv.Recover(ctx))
+
+	for _, name := range []string{"engine.1", "engine.3", "engine.4"} {
+		st, err := engines.Get(ctx, name)
+		assert.NoError(t, err)
+		assert.EqualValues(t, v1.EnginePhase_PHASE_DONE, st.Phase)
+		assert.False(t, st.Conditions.Success)
+		assert.EqualValues(t, 1, st.Conditions.FailureCount)
+		assert.NotNil(t, st.Metadata.Finished)
+	}
+
+	var st *v1.EngineStatus
+	for i := 0; i < 100; i++ {
+		var err error
+		st, err = engines.Get(ctx, "engine.2")
+		assert.NoError(t, err)
+		if st.Phase == v1.EnginePhase_PHASE_DONE {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.EqualValues(t, v1.EnginePhase_PHASE_DONE, st.Phase)
+	assert.True(t, st.Conditions.Success)
+	assert.True(t, st.Conditions.DidExecute)
+}
+
+func TestService_WaitUntil(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	client := newTestClient(t, executor)
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{
+		EngineYaml: []byte(testEngineYAML),
+		WaitUntil:  timestamppb.New(time.Now().Add(200 * time.Millisecond)),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, v1.EnginePhase_PHASE_WAITING, resp.Status.Phase)
+	assert.NotNil(t, resp.Status.Conditions.WaitUntil)
+	st := waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.True(t, st.Conditions.Success)
+	assert.True(t, st.Conditions.DidExecute)
+
+	resp, err = client.StartEngine(ctx, &v1.StartEngineRequest{
+		EngineYaml: []byte(testEngineYAML),
+		WaitUntil:  timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	assert.NoError(t, err)
+	_, err = client.StopEngine(ctx, &v1.StopEngineRequest{Name: resp.Status.Name})
+	assert.NoError(t, err)
+	st = waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.False(t, st.Conditions.Success)
+	assert.False(t, st.Conditions.DidExecute)
+	assert.EqualValues(t, "engine was stopped while waiting", st.Details)
+
+	// engines whose time has already come start right away
+	resp, err = client.StartEngine(ctx, &v1.StartEngineRequest{
+		EngineYaml: []byte(testEngineYAML),
+		WaitUntil:  timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, v1.EnginePhase_PHASE_PREPARING, resp.Status.Phase)
+}
+
+func TestService_StopEngine(t *testing.T) {
+	client := newTestClient(t, &fakeExecutor{release: make(chan struct{})})
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML)})
+	assert.NoError(t, err)
+	waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_RUNNING)
+	_, err = client.StopEngine(ctx, &v1.StopEngineRequest{Name: resp.Status.Name})
+	assert.NoError(t, err)
+	st := waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.False(t, st.Conditions.Success)
+	assert.EqualValues(t, 1, st.Conditions.FailureCount)
+
+	_, err = client.StopEngine(ctx, &v1.StopEngineRequest{Name: resp.Status.Name})
+	assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+	_, err = client.StopEngine(ctx, &v1.StopEngineRequest{Name: "does-not-exist.1"})
+	assert.EqualValues(t, codes.NotFound, status.Code(err))
+}
+
+func TestService_Listen(t *testing.T) {
+	tests := []struct {
+		Mode     v1.ListenRequestLogs
+		Expected []string
+	}{
+		{
+			Mode: v1.ListenRequestLogs_LOGS_UNSLICED,
+			Expected: []string{
+				"CONTENT : [greet] \x1b[32mhello\x1b[0m from the engine\n[greet|DONE]\n[rows|RESULT] 42 rows were migrated\nengine done\n",
+			},
+		},
+		{
+			Mode: v1.ListenRequestLogs_LOGS_RAW,
+			Expected: []string{
+				"START greet: ",
+				"CONTENT greet: \x1b[32mhello\x1b[0m from the engine",
+				"DONE greet: ",
+				"RESULT rows: 42 rows were migrated",
+				"CONTENT : engine done",
+			},
+		},
+		{
+			Mode: v1.ListenRequestLogs_LOGS_HTML,
+			Expected: []string{
+				"START greet: ",
+				`CONTENT greet: <span class="term-fg32">hello</span> from the engine`,
+				"DONE greet: ",
+				"RESULT rows: 42 rows were migrated",
+				"CONTENT : engine done",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Mode.String(), func(t *testing.T) {
+			executor := &fakeExecutor{release: make(chan struct{})}
+			client := newTestClient(t, executor)
+			ctx := context.Background()
+
+			resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML)})
+			assert.NoError(t, err)
+			waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_RUNNING)
+			stream, err := client.Listen(ctx, &v1.ListenRequest{
+				Name:    resp.Status.Name,
+				Updates: true,
+				Logs:    test.Mode,
+			})
+			assert.NoError(t, err)
+			close(executor.release)
+
+			var (
+				slices   []string
+				unsliced string
+				lastSeen v1.EnginePhase
+			)
+			for {
+				msg, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				switch c := msg.Content.(type) {
+				case *v1.ListenResponse_Update:
+					lastSeen = c.Update.Phase
+				case *v1.ListenResponse_Slice:
+					if test.Mode == v1.ListenRequestLogs_LOGS_UNSLICED {
+						unsliced += c.Slice.Payload
+						continue
+					}
+					slices = append(slices, fmt.Sprintf("%s %s: %s", strings.TrimPrefix(c.Slice.Type.String(), "SLICE_"), c.Slice.Name, c.Slice.Payload))
+				}
+			}
+			if unsliced != "" {
+				slices = []string{"CONTENT : " + unsliced}
+			}
+			assert.EqualValues(t, v1.EnginePhase_PHASE_DONE, lastSeen)
+			assert.Equal(t, test.Expected, slices)
+		})
+	}
+}
+
+func TestService_LogRetention(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	client := newTestClientWithConfig(t, Config{LogRetention: 50 * time.Millisecond}, executor)
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML)})
+	assert.NoError(t, err)
+	close(executor.release)
+	waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+
+	listenLogs := func() error {
+		stream, err := client.Listen(ctx, &v1.ListenRequest{Name: resp.Status.Name, Logs: v1.ListenRequestLogs_LOGS_RAW})
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	assert.NoError(t, listenLogs())
+
+	// the finished engine is forgotten, its status is still stored
+	time.Sleep(200 * time.Millisecond)
+	assert.EqualValues(t, codes.NotFound, status.Code(listenLogs()))
+	st := waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_DONE)
+	assert.True(t, st.Conditions.Success)
+}
+
+func TestService_Subscribe(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	close(executor.release)
+	client := newTestClient(t, executor)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Subscribe(ctx, &v1.SubscribeRequest{
+		Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "spec", Value: "wanted"}}}},
+	})
+	assert.NoError(t, err)
+	// the subscription is only registered once the server has seen the request
+	time.Sleep(50 * time.Millisecond)
+
+	for _, spec := range []string{"unwanted", "wanted"} {
+		_, err := client.StartEngine(ctx, &v1.StartEngineRequest{
+			Metadata:   &v1.EngineMetadata{EngineSpecName: spec},
+			EngineYaml: []byte(testEngineYAML),
+		})
+		assert.NoError(t, err)
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.EqualValues(t, "wanted.1", msg.Result.Name)
+		if msg.Result.Phase == v1.EnginePhase_PHASE_DONE {
+			break
+		}
+	}
+
+	stream, err = client.Subscribe(ctx, &v1.SubscribeRequest{
+		Filter: []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "does-not-exist"}}}},
+	})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_Shutdown(t *testing.T) {
+	executor := &fakeExecutor{release: make(chan struct{})}
+	srv, client := newTestService(t, Config{}, executor)
+	ctx := context.Background()
+
+	resp, err := client.StartEngine(ctx, &v1.StartEngineRequest{EngineYaml: []byte(testEngineYAML)})
+	assert.NoError(t, err)
+	waitForPhase(t, client, resp.Status.Name, v1.EnginePhase_PHASE_RUNNING)
+	subscription, err := client.Subscribe(ctx, &v1.SubscribeRequest{})
+	assert.NoError(t, err)
+	listener, err := client.Listen(ctx, &v1.ListenRequest{Name: resp.Status.Name, Logs: v1.ListenRequestLogs_LOGS_RAW})
+	assert.NoError(t, err)
+	// the streams are only set up once the server has seen the requests
+	time.Sleep(50 * time.Millisecond)
+
+	srv.Shutdown()
+	_, err = subscription.Recv()
+	assert.EqualValues(t, codes.Unavailable, status.Code(err))
+	for {
+		if _, err = listener.Recv(); err != nil {
+			break
+		}
+	}
+	assert.EqualValues(t, codes.Unavailable, status.Code(err))
+	close(executor.release)
+}
@@ -0,0 +1,87 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Spec describes the work a Bhojpur SQL engine performs. It is the content of
+// the engine YAML passed to StartEngine or referenced by sql/config.yaml.
+type Spec struct {
+	// Description is a human readable explanation of what the engine does
+	Description string `json:"description,omitempty"`
+	// Driver is the database/sql driver used to connect, e.g. postgres
+	Driver string `json:"driver"`
+	// DSN is the data source name. The environment variables the server allows, see
+	// SQLExecutor.DSNEnv, are expanded.
+	DSN string `json:"dsn"`
+	// Statements are executed in order before any of the files
+	Statements []string `json:"statements,omitempty"`
+	// Files are glob patterns relative to the engine workspace. Matching files
+	// are executed in lexical order.
+	Files []string `json:"files,omitempty"`
+}
+
+// ParseSpec parses and validates an engine YAML document
+func ParseSpec(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("cannot parse engine spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks if the spec is complete
+func (s *Spec) Validate() error {
+	if strings.TrimSpace(s.Driver) == "" {
+		return fmt.Errorf("engine spec has no driver")
+	}
+	if strings.TrimSpace(s.DSN) == "" {
+		return fmt.Errorf("engine spec has no dsn")
+	}
+	if len(s.Statements) == 0 && len(s.Files) == 0 {
+		return fmt.Errorf("engine spec has neither statements nor files")
+	}
+	return nil
+}
+
+// RepoConfig is the content of the sql/config.yaml file of a repository
+type RepoConfig struct {
+	// DefaultEngine is the path of the engine YAML which is started by default,
+	// relative to the repository root
+	DefaultEngine string `json:"defaultEngine"`
+}
+
+// ParseRepoConfig parses a sql/config.yaml document
+func ParseRepoConfig(data []byte) (*RepoConfig, error) {
+	var cfg RepoConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse config.yaml: %w", err)
+	}
+	return &cfg, nil
+}
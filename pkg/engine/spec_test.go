@@ -0,0 +1,50 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+description: nightly maintenance
+driver: postgres
+dsn: ${DATABASE_URL}
+statements:
+- VACUUM ANALYZE
+files:
+- migrations/*.sql
+`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "postgres", spec.Driver)
+	assert.EqualValues(t, []string{"VACUUM ANALYZE"}, spec.Statements)
+	assert.EqualValues(t, []string{"migrations/*.sql"}, spec.Files)
+
+	_, err = ParseSpec([]byte("driver: postgres\ndsn: x\n"))
+	assert.Error(t, err)
+	_, err = ParseSpec([]byte("dsn: x\nstatements: [SELECT 1]\n"))
+	assert.Error(t, err)
+	_, err = ParseSpec([]byte("driver: postgres\ndsn: x\nstatements: [SELECT 1]\nunknown: field\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,240 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultMaxUploadSize is the default size limit of the gzipped application tar
+	// uploaded to StartLocalEngine
+	DefaultMaxUploadSize = 64 << 20
+	// DefaultMaxWorkspaceSize is the default size limit of an extracted engine workspace
+	DefaultMaxWorkspaceSize = 512 << 20
+
+	// maxYAMLSize limits the size of the config and engine YAML uploaded to StartLocalEngine
+	maxYAMLSize = 1 << 20
+)
+
+// uploadStage is the part of a StartLocalEngine upload a message belongs to.
+// The stages must be uploaded in order.
+type uploadStage int
+
+const (
+	stageNone uploadStage = iota
+	stageMetadata
+	stageConfig
+	stageEngine
+	stageApplication
+	stageDone
+)
+
+func (s uploadStage) String() string {
+	switch s {
+	case stageMetadata:
+		return "metadata"
+	case stageConfig:
+		return "config_yaml"
+	case stageEngine:
+		return "engine_yaml"
+	case stageApplication:
+		return "application_tar"
+	case stageDone:
+		return "application_tar_done"
+	}
+	return "nothing"
+}
+
+// repeatable returns true if the content of a stage may be split across several messages
+func (s uploadStage) repeatable() bool {
+	return s == stageConfig || s == stageEngine || s == stageApplication
+}
+
+// expectation describes which messages may follow a message of this stage
+func (s uploadStage) expectation() string {
+	switch {
+	case s == stageDone:
+		return "the end of the upload"
+	case s.repeatable():
+		return s.String() + " or " + (s + 1).String()
+	default:
+		return (s + 1).String()
+	}
+}
+
+// localUpload is the content of a StartLocalEngine upload
+type localUpload struct {
+	Metadata   *v1.EngineMetadata
+	ConfigYAML []byte
+	EngineYAML []byte
+	// Application is the gzipped application tar
+	Application *os.File
+}
+
+// Close removes the uploaded application tar
+func (u *localUpload) Close() error {
+	u.Application.Close()
+	return os.Remove(u.Application.Name())
+}
+
+// receiveUpload receives all messages of a StartLocalEngine upload. It enforces their order
+// and writes the application tar to a temporary file.
+func (srv *Service) receiveUpload(inc v1.SqlService_StartLocalEngineServer) (_ *localUpload, err error) {
+	app, err := ioutil.TempFile(srv.Config.WorkDir, "upload-*.tar.gz")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot store application_tar: %v", err)
+	}
+	res := &localUpload{Application: app}
+	defer func() {
+		if err != nil {
+			res.Close()
+		}
+	}()
+
+	var (
+		stage   = stageNone
+		appSize int64
+	)
+	for {
+		req, err := inc.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		next := stageOf(req)
+		if next == stageNone {
+			return nil, status.Error(codes.InvalidArgument, "upload contains an empty message")
+		}
+		if next != stage+1 && (next != stage || !next.repeatable()) {
+			return nil, status.Errorf(codes.InvalidArgument, "expected %s but received %s", stage.expectation(), next)
+		}
+		stage = next
+
+		switch c := req.Content.(type) {
+		case *v1.StartLocalEngineRequest_Metadata:
+			res.Metadata = c.Metadata
+		case *v1.StartLocalEngineRequest_ConfigYaml:
+			res.ConfigYAML, err = appendLimited(res.ConfigYAML, c.ConfigYaml, maxYAMLSize, stage)
+		case *v1.StartLocalEngineRequest_EngineYaml:
+			res.EngineYAML, err = appendLimited(res.EngineYAML, c.EngineYaml, maxYAMLSize, stage)
+		case *v1.StartLocalEngineRequest_ApplicationTar:
+			appSize += int64(len(c.ApplicationTar))
+			if appSize > srv.Config.MaxUploadSize {
+				return nil, status.Errorf(codes.ResourceExhausted, "application_tar exceeds %d bytes", srv.Config.MaxUploadSize)
+			}
+			if _, err = res.Application.Write(c.ApplicationTar); err != nil {
+				return nil, status.Errorf(codes.Internal, "cannot store application_tar: %v", err)
+			}
+		case *v1.StartLocalEngineRequest_ApplicationTarDone:
+			if !c.ApplicationTarDone {
+				return nil, status.Error(codes.InvalidArgument, "application_tar_done must be true")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if stage != stageDone {
+		return nil, status.Errorf(codes.InvalidArgument, "upload ended early: expected %s", stage.expectation())
+	}
+
+	if _, err := res.Application.Seek(0, io.SeekStart); err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot read application_tar: %v", err)
+	}
+	return res, nil
+}
+
+func stageOf(req *v1.StartLocalEngineRequest) uploadStage {
+	switch req.Content.(type) {
+	case *v1.StartLocalEngineRequest_Metadata:
+		return stageMetadata
+	case *v1.StartLocalEngineRequest_ConfigYaml:
+		return stageConfig
+	case *v1.StartLocalEngineRequest_EngineYaml:
+		return stageEngine
+	case *v1.StartLocalEngineRequest_ApplicationTar:
+		return stageApplication
+	case *v1.StartLocalEngineRequest_ApplicationTarDone:
+		return stageDone
+	}
+	return stageNone
+}
+
+func appendLimited(buf, data []byte, limit int, stage uploadStage) ([]byte, error) {
+	if len(buf)+len(data) > limit {
+		return nil, status.Errorf(codes.ResourceExhausted, "%s exceeds %d bytes", stage, limit)
+	}
+	return append(buf, data...), nil
+}
+
+// StartLocalEngine starts an engine from a working copy uploaded by the client
+func (srv *Service) StartLocalEngine(inc v1.SqlService_StartLocalEngineServer) error {
+	upload, err := srv.receiveUpload(inc)
+	if err != nil {
+		return err
+	}
+	defer upload.Close()
+
+	cfg, err := ParseRepoConfig(upload.ConfigYAML)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	spec, err := ParseSpec(upload.EngineYAML)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	md := &v1.EngineMetadata{}
+	if upload.Metadata != nil {
+		md = proto.Clone(upload.Metadata).(*v1.EngineMetadata)
+	}
+	if md.EngineSpecName == "" && cfg.DefaultEngine != "" {
+		md.EngineSpecName = strings.TrimSuffix(filepath.Base(cfg.DefaultEngine), filepath.Ext(cfg.DefaultEngine))
+	}
+
+	// the workspace is extracted from the spooled application tar, an empty one leaves it empty
+	info, err := upload.Application.Stat()
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot read application_tar: %v", err)
+	}
+	var sideload io.ReadSeeker
+	if info.Size() > 0 {
+		sideload = upload.Application
+	}
+	st, err := srv.start(inc.Context(), engineBaseName(md, ""), md, upload.EngineYAML, spec, sideload, nil)
+	if err != nil {
+		return err
+	}
+	return inc.SendAndClose(&v1.StartEngineResponse{Status: st})
+}
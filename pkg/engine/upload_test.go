@@ -0,0 +1,157 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// workspaceExecutor reports the content of migrate.sql in the engine workspace
+type workspaceExecutor struct {
+	content chan string
+}
+
+func (w *workspaceExecutor) Execute(ctx context.Context, spec *Spec, workdir string, out io.Writer) error {
+	content, err := ioutil.ReadFile(filepath.Join(workdir, "migrate.sql"))
+	if err != nil {
+		return err
+	}
+	w.content <- string(content)
+	return nil
+}
+
+func upload(ctx context.Context, client v1.SqlServiceClient, msgs ...*v1.StartLocalEngineRequest) (*v1.StartEngineResponse, error) {
+	stream, err := client.StartLocalEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range msgs {
+		if err := stream.Send(msg); err == io.EOF {
+			// the server has already rejected the upload
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func TestService_StartLocalEngine(t *testing.T) {
+	var (
+		metadata = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_Metadata{
+			Metadata: &v1.EngineMetadata{Owner: "tester"},
+		}}
+		config = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ConfigYaml{
+			ConfigYaml: []byte("defaultEngine: sql/engines/migrate.yaml\n"),
+		}}
+		engine = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_EngineYaml{
+			EngineYaml: []byte(testEngineYAML),
+		}}
+		app       = tarGz(t, map[string]string{"migrate.sql": "SELECT 1;"})
+		appChunk1 = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ApplicationTar{ApplicationTar: app[:10]}}
+		appChunk2 = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ApplicationTar{ApplicationTar: app[10:]}}
+		done      = &v1.StartLocalEngineRequest{Content: &v1.StartLocalEngineRequest_ApplicationTarDone{ApplicationTarDone: true}}
+	)
+
+	tests := []struct {
+		Name     string
+		Config   Config
+		Messages []*v1.StartLocalEngineRequest
+		Code     codes.Code
+	}{
+		{Name: "complete", Messages: []*v1.StartLocalEngineRequest{metadata, config, engine, appChunk1, appChunk2, done}},
+		{Name: "missing metadata", Messages: []*v1.StartLocalEngineRequest{config, engine, appChunk1, appChunk2, done}, Code: codes.InvalidArgument},
+		{Name: "out of order", Messages: []*v1.StartLocalEngineRequest{metadata, engine, config, appChunk1, appChunk2, done}, Code: codes.InvalidArgument},
+		{Name: "metadata twice", Messages: []*v1.StartLocalEngineRequest{metadata, metadata, config, engine, appChunk1, appChunk2, done}, Code: codes.InvalidArgument},
+		{Name: "after done", Messages: []*v1.StartLocalEngineRequest{metadata, config, engine, appChunk1, appChunk2, done, appChunk2}, Code: codes.InvalidArgument},
+		{Name: "incomplete", Messages: []*v1.StartLocalEngineRequest{metadata, config, engine, appChunk1}, Code: codes.InvalidArgument},
+		{Name: "empty message", Messages: []*v1.StartLocalEngineRequest{metadata, {}}, Code: codes.InvalidArgument},
+		{
+			Name:     "invalid engine",
+			Messages: []*v1.StartLocalEngineRequest{metadata, config, {Content: &v1.StartLocalEngineRequest_EngineYaml{EngineYaml: []byte("driver: postgres")}}, appChunk1, appChunk2, done},
+			Code:     codes.InvalidArgument,
+		},
+		{
+			Name:     "upload too large",
+			Config:   Config{MaxUploadSize: int64(len(app) - 1)},
+			Messages: []*v1.StartLocalEngineRequest{metadata, config, engine, appChunk1, appChunk2, done},
+			Code:     codes.ResourceExhausted,
+		},
+		{
+			Name:     "workspace too large",
+			Config:   Config{MaxWorkspaceSize: 5},
+			Messages: []*v1.StartLocalEngineRequest{metadata, config, engine, appChunk1, appChunk2, done},
+			Code:     codes.ResourceExhausted,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			executor := &workspaceExecutor{content: make(chan string, 1)}
+			client := newTestClientWithConfig(t, test.Config, executor)
+
+			resp, err := upload(context.Background(), client, test.Messages...)
+			if test.Code != codes.OK {
+				assert.EqualValues(t, test.Code, status.Code(err), "unexpected error: %v", err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.EqualValues(t, "migrate.1", resp.Status.Name)
+			assert.EqualValues(t, "tester", resp.Status.Metadata.Owner)
+			assert.EqualValues(t, "SELECT 1;", <-executor.content)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the file in the root of a working copy which lists the paths
+// that are not uploaded when starting a local engine
+const IgnoreFile = ".sqlignore"
+
+// IgnoreList decides which paths of a working copy are left out of a workspace archive.
+// It understands a subset of the gitignore syntax: # starts a comment, ! negates
+// a pattern, a trailing / only matches directories, and patterns containing a /
+// are relative to the root while all others match names at any depth.
+type IgnoreList struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnoreList parses ignore patterns, one per line
+func ParseIgnoreList(r io.Reader) (*IgnoreList, error) {
+	var (
+		res  IgnoreList
+		scan = bufio.NewScanner(r)
+	)
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		p.anchored = strings.Contains(line, "/")
+		p.pattern = strings.TrimPrefix(line, "/")
+		if _, err := path.Match(p.pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", scan.Text(), err)
+		}
+		res.patterns = append(res.patterns, p)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Ignores returns true if the slash separated path relative to the root of the working
+// copy should be ignored. Later patterns take precedence over earlier ones.
+func (l *IgnoreList) Ignores(name string, isDir bool) bool {
+	if l == nil {
+		return false
+	}
+	var ignored bool
+	for _, p := range l.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		subject := name
+		if !p.anchored {
+			subject = path.Base(name)
+		}
+		if ok, _ := path.Match(p.pattern, subject); ok {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// ArchiveWorkspace writes a gzipped tar of the regular files and directories in dir to w.
+// Paths matched by ignore are left out, as is the .git directory.
+func ArchiveWorkspace(dir string, ignore *IgnoreList, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.Walk(dir, func(fn string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fn)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+		if name == ".git" || ignore.Ignores(name, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			// links and devices have no place in an engine workspace
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(fn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// errWorkspaceTooLarge is returned when an archive extracts to more than the permitted size
+var errWorkspaceTooLarge = errors.New("workspace is too large")
+
+// extractTarGz extracts a gzipped tar stream into dst. Entries which would
+// end up outside of dst are rejected, as are archives whose content exceeds
+// maxSize bytes. If maxSize is zero, the size is not limited.
+func extractTarGz(r io.Reader, dst string, maxSize int64) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("cannot decompress archive: %w", err)
+	}
+	defer gz.Close()
+
+	dst = filepath.Clean(dst)
+	tr := tar.NewReader(gz)
+	var size int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read archive: %w", err)
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(hdr.Name))
+		if target != dst && !strings.HasPrefix(target, dst+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside of the workspace", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			size += hdr.Size
+			if maxSize > 0 && size > maxSize {
+				return fmt.Errorf("%w: content exceeds %d bytes", errWorkspaceTooLarge, maxSize)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode)&0755|0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			// links and devices have no place in an engine workspace
+		}
+	}
+}
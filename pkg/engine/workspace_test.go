@@ -0,0 +1,115 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreList(t *testing.T) {
+	ignore, err := ParseIgnoreList(strings.NewReader(`
+# build output
+*.log
+!keep.log
+/build
+tmp/
+docs/*.md
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		Name    string
+		IsDir   bool
+		Ignored bool
+	}{
+		{Name: "migrate.sql"},
+		{Name: "debug.log", Ignored: true},
+		{Name: "sub/debug.log", Ignored: true},
+		{Name: "sub/keep.log"},
+		{Name: "build", IsDir: true, Ignored: true},
+		{Name: "sub/build", IsDir: true},
+		{Name: "sub/tmp", IsDir: true, Ignored: true},
+		{Name: "tmp"},
+		{Name: "docs/readme.md", Ignored: true},
+		{Name: "sub/docs/readme.md"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Ignored, ignore.Ignores(test.Name, test.IsDir))
+		})
+	}
+
+	_, err = ParseIgnoreList(strings.NewReader("[unterminated"))
+	assert.Error(t, err)
+}
+
+func TestArchiveWorkspace(t *testing.T) {
+	src := t.TempDir()
+	for fn, content := range map[string]string{
+		"sql/config.yaml":         "defaultEngine: sql/migrate.yaml",
+		"migrations/0001.sql":     "SELECT 1;",
+		"migrations/debug.log":    "ignored",
+		".git/HEAD":               "ignored",
+		"node_modules/x/index.js": "ignored",
+	} {
+		fn = filepath.Join(src, fn)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignore, err := ParseIgnoreList(strings.NewReader("*.log\nnode_modules/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, ArchiveWorkspace(src, ignore, &buf)) {
+		return
+	}
+	dst := t.TempDir()
+	if !assert.NoError(t, extractTarGz(&buf, dst, 0)) {
+		return
+	}
+
+	var files []string
+	filepath.Walk(dst, func(fn string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dst, fn)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	sort.Strings(files)
+	assert.Equal(t, []string{"migrations/0001.sql", "sql/config.yaml"}, files)
+}
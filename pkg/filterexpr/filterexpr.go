@@ -0,0 +1,165 @@
+package filterexpr
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+)
+
+// AnnotationPrefix is the field prefix which refers to engine annotations,
+// e.g. annotation.ticket
+const AnnotationPrefix = "annotation."
+
+// Fields are the engine status fields filter terms and order expressions can refer to.
+// Besides these, fields starting with AnnotationPrefix refer to annotations.
+var Fields = []string{
+	"name",
+	"owner",
+	"phase",
+	"trigger",
+	"success",
+	"spec",
+	"repo.host",
+	"repo.owner",
+	"repo.repo",
+	"repo.ref",
+	"repo.revision",
+}
+
+// IsValidField returns true if field can be used in a filter term
+func IsValidField(field string) bool {
+	if strings.HasPrefix(field, AnnotationPrefix) {
+		return len(field) > len(AnnotationPrefix)
+	}
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidOrderField returns true if field can be used in an order expression.
+// Besides all filter fields, engines can be ordered by their created and finished time.
+func IsValidOrderField(field string) bool {
+	return field == "created" || field == "finished" || IsValidField(field)
+}
+
+// FieldValue returns the value of a filter field of an engine status
+func FieldValue(status *v1.EngineStatus, field string) (value string, exists bool) {
+	value, exists = index(status)[field]
+	return
+}
+
+// PhaseValue returns the filter value of an engine phase, e.g. running
+func PhaseValue(phase v1.EnginePhase) string {
+	return strings.ToLower(strings.TrimPrefix(phase.String(), "PHASE_"))
+}
+
+// TriggerValue returns the filter value of an engine trigger, e.g. push
+func TriggerValue(trigger v1.EngineTrigger) string {
+	return strings.ToLower(strings.TrimPrefix(trigger.String(), "TRIGGER_"))
+}
+
+// index produces the field values of an engine status. Fields which are not
+// set are absent from the index.
+func index(status *v1.EngineStatus) map[string]string {
+	idx := map[string]string{
+		"name":  status.Name,
+		"phase": PhaseValue(status.Phase),
+	}
+	if c := status.Conditions; c != nil {
+		idx["success"] = strconv.FormatBool(c.Success)
+	}
+	if md := status.Metadata; md != nil {
+		idx["owner"] = md.Owner
+		idx["trigger"] = TriggerValue(md.Trigger)
+		idx["spec"] = md.EngineSpecName
+		if repo := md.Repository; repo != nil {
+			idx["repo.host"] = repo.Host
+			idx["repo.owner"] = repo.Owner
+			idx["repo.repo"] = repo.Repo
+			idx["repo.ref"] = repo.Ref
+			idx["repo.revision"] = repo.Revision
+		}
+		for _, a := range md.Annotations {
+			idx[AnnotationPrefix+a.Key] = a.Value
+		}
+	}
+	for k, v := range idx {
+		if v == "" && !strings.HasPrefix(k, AnnotationPrefix) {
+			delete(idx, k)
+		}
+	}
+	return idx
+}
+
+// MatchesFilter returns true if the engine status matches the filter.
+// All expressions of the filter must match. An expression matches if any
+// of its terms matches. An empty filter matches everything.
+func MatchesFilter(status *v1.EngineStatus, filter []*v1.FilterExpression) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	if status == nil {
+		return false
+	}
+
+	idx := index(status)
+	for _, expr := range filter {
+		var matches bool
+		for _, term := range expr.Terms {
+			if matchesTerm(idx, term) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesTerm(idx map[string]string, term *v1.FilterTerm) bool {
+	val, exists := idx[term.Field]
+
+	var res bool
+	switch term.Operation {
+	case v1.FilterOp_OP_EXISTS:
+		res = exists
+	case v1.FilterOp_OP_EQUALS:
+		res = exists && val == term.Value
+	case v1.FilterOp_OP_STARTS_WITH:
+		res = exists && strings.HasPrefix(val, term.Value)
+	case v1.FilterOp_OP_ENDS_WITH:
+		res = exists && strings.HasSuffix(val, term.Value)
+	case v1.FilterOp_OP_CONTAINS:
+		res = exists && strings.Contains(val, term.Value)
+	}
+	if term.Negate {
+		res = !res
+	}
+	return res
+}
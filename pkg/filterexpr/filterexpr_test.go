@@ -0,0 +1,138 @@
+package filterexpr
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	status := &v1.EngineStatus{
+		Name:  "migrate-nightly.12",
+		Phase: v1.EnginePhase_PHASE_RUNNING,
+		Metadata: &v1.EngineMetadata{
+			Owner:       "dba",
+			Trigger:     v1.EngineTrigger_TRIGGER_MANUAL,
+			Repository:  &v1.Repository{Repo: "inventory", Ref: "refs/heads/main"},
+			Annotations: []*v1.Annotation{{Key: "ticket", Value: "OPS-42"}},
+		},
+		Conditions: &v1.EngineConditions{},
+	}
+	term := func(field string, op v1.FilterOp, value string, negate bool) *v1.FilterTerm {
+		return &v1.FilterTerm{Field: field, Operation: op, Value: value, Negate: negate}
+	}
+	var cases = []struct {
+		name    string
+		filter  []*v1.FilterExpression
+		matches bool
+	}{
+		{"empty", nil, true},
+		{"equals", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("phase", v1.FilterOp_OP_EQUALS, "running", false)}}}, true},
+		{"negated equals", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("phase", v1.FilterOp_OP_EQUALS, "running", true)}}}, false},
+		{"starts with", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("name", v1.FilterOp_OP_STARTS_WITH, "migrate-", false)}}}, true},
+		{"ends with", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("repo.ref", v1.FilterOp_OP_ENDS_WITH, "/main", false)}}}, true},
+		{"contains", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("repo.repo", v1.FilterOp_OP_CONTAINS, "vent", false)}}}, true},
+		{"annotation", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("annotation.ticket", v1.FilterOp_OP_EQUALS, "OPS-42", false)}}}, true},
+		{"exists", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("annotation.ticket", v1.FilterOp_OP_EXISTS, "", false)}}}, true},
+		{"not exists", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{term("repo.host", v1.FilterOp_OP_EXISTS, "", false)}}}, false},
+		{"terms are or'ed", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+			term("owner", v1.FilterOp_OP_EQUALS, "someone", false),
+			term("owner", v1.FilterOp_OP_EQUALS, "dba", false),
+		}}}, true},
+		{"expressions are and'ed", []*v1.FilterExpression{
+			{Terms: []*v1.FilterTerm{term("owner", v1.FilterOp_OP_EQUALS, "dba", false)}},
+			{Terms: []*v1.FilterTerm{term("trigger", v1.FilterOp_OP_EQUALS, "push", false)}},
+		}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.EqualValues(t, c.matches, MatchesFilter(status, c.filter))
+		})
+	}
+}
+
+func TestIsValidField(t *testing.T) {
+	assert.True(t, IsValidField("repo.owner"))
+	assert.True(t, IsValidField("annotation.ticket"))
+	assert.False(t, IsValidField("annotation."))
+	assert.False(t, IsValidField("name; DROP TABLE engine_status"))
+	assert.False(t, IsValidField("created"))
+	assert.True(t, IsValidOrderField("created"))
+}
+
+func TestParseFilter(t *testing.T) {
+	var cases = []struct {
+		name     string
+		exprs    []string
+		expected []*v1.FilterExpression
+		err      bool
+	}{
+		{"equals", []string{"phase==running"}, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "phase", Value: "running"}}}}, false},
+		{"not equals", []string{"phase!=done"}, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "phase", Value: "done", Negate: true}}}}, false},
+		{"operators", []string{"name^=migrate-", "repo.ref$=/main", "repo.repo~=vent"}, []*v1.FilterExpression{
+			{Terms: []*v1.FilterTerm{{Field: "name", Value: "migrate-", Operation: v1.FilterOp_OP_STARTS_WITH}}},
+			{Terms: []*v1.FilterTerm{{Field: "repo.ref", Value: "/main", Operation: v1.FilterOp_OP_ENDS_WITH}}},
+			{Terms: []*v1.FilterTerm{{Field: "repo.repo", Value: "vent", Operation: v1.FilterOp_OP_CONTAINS}}},
+		}, false},
+		{"exists", []string{"annotation.ticket?", "!repo.host?"}, []*v1.FilterExpression{
+			{Terms: []*v1.FilterTerm{{Field: "annotation.ticket", Operation: v1.FilterOp_OP_EXISTS}}},
+			{Terms: []*v1.FilterTerm{{Field: "repo.host", Operation: v1.FilterOp_OP_EXISTS, Negate: true}}},
+		}, false},
+		{"negated not equals", []string{"!phase!=done"}, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "phase", Value: "done"}}}}, false},
+		{"or", []string{"phase==running, phase==preparing"}, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{
+			{Field: "phase", Value: "running"},
+			{Field: "phase", Value: "preparing"},
+		}}}, false},
+		{"value with operator", []string{"annotation.query==a==b"}, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "annotation.query", Value: "a==b"}}}}, false},
+		{"no operator", []string{"phase"}, nil, true},
+		{"unknown field", []string{"password==secret"}, nil, true},
+		{"empty field", []string{"==running"}, nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := ParseFilter(c.exprs)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, act)
+		})
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	act, err := ParseOrder([]string{"created:desc", "name", "annotation.ticket:ASC"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*v1.OrderExpression{
+		{Field: "created"},
+		{Field: "name", Ascending: true},
+		{Field: "annotation.ticket", Ascending: true},
+	}, act)
+
+	_, err = ParseOrder([]string{"created:sideways"})
+	assert.Error(t, err)
+	_, err = ParseOrder([]string{"password"})
+	assert.Error(t, err)
+}
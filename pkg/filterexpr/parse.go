@@ -0,0 +1,120 @@
+package filterexpr
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+)
+
+// operators maps the textual filter operators to their operation and negation
+var operators = []struct {
+	Op        string
+	Operation v1.FilterOp
+	Negate    bool
+}{
+	{"==", v1.FilterOp_OP_EQUALS, false},
+	{"!=", v1.FilterOp_OP_EQUALS, true},
+	{"^=", v1.FilterOp_OP_STARTS_WITH, false},
+	{"$=", v1.FilterOp_OP_ENDS_WITH, false},
+	{"~=", v1.FilterOp_OP_CONTAINS, false},
+}
+
+// ParseTerm parses a single filter term. Terms have the form field==value, where
+// the operator is one of == (equals), != (does not equal), ^= (starts with),
+// $= (ends with) or ~= (contains). field? tests if the field exists. A leading
+// ! negates the term, e.g. !annotation.ticket?
+func ParseTerm(s string) (*v1.FilterTerm, error) {
+	expr := strings.TrimSpace(s)
+	var negate bool
+	if strings.HasPrefix(expr, "!") {
+		negate = true
+		expr = expr[1:]
+	}
+
+	var (
+		term  = &v1.FilterTerm{}
+		found bool
+	)
+scan:
+	for i := 0; i+1 < len(expr); i++ {
+		for _, op := range operators {
+			if expr[i:i+2] == op.Op {
+				term.Field, term.Value = expr[:i], expr[i+2:]
+				term.Operation, term.Negate = op.Operation, op.Negate
+				found = true
+				break scan
+			}
+		}
+	}
+	if !found {
+		if !strings.HasSuffix(expr, "?") {
+			return nil, fmt.Errorf("invalid filter term %q: expected field==value, field? or another operator", s)
+		}
+		term.Field, term.Operation = strings.TrimSuffix(expr, "?"), v1.FilterOp_OP_EXISTS
+	}
+	if !IsValidField(term.Field) {
+		return nil, fmt.Errorf("invalid filter term %q: cannot filter by %q", s, term.Field)
+	}
+	if negate {
+		term.Negate = !term.Negate
+	}
+	return term, nil
+}
+
+// ParseFilter parses filter expressions. Each expression consists of comma separated
+// terms of which any must match. All expressions must match.
+func ParseFilter(exprs []string) ([]*v1.FilterExpression, error) {
+	res := make([]*v1.FilterExpression, 0, len(exprs))
+	for _, e := range exprs {
+		var expr v1.FilterExpression
+		for _, t := range strings.Split(e, ",") {
+			term, err := ParseTerm(t)
+			if err != nil {
+				return nil, err
+			}
+			expr.Terms = append(expr.Terms, term)
+		}
+		res = append(res, &expr)
+	}
+	return res, nil
+}
+
+// ParseOrder parses order expressions of the form field, field:asc or field:desc
+func ParseOrder(exprs []string) ([]*v1.OrderExpression, error) {
+	res := make([]*v1.OrderExpression, 0, len(exprs))
+	for _, e := range exprs {
+		field, dir := e, "asc"
+		if idx := strings.LastIndex(e, ":"); idx >= 0 {
+			field, dir = e[:idx], strings.ToLower(e[idx+1:])
+		}
+		if dir != "asc" && dir != "desc" {
+			return nil, fmt.Errorf("invalid order %q: direction must be asc or desc", e)
+		}
+		if !IsValidOrderField(field) {
+			return nil, fmt.Errorf("invalid order %q: cannot order by %q", e, field)
+		}
+		res = append(res, &v1.OrderExpression{Field: field, Ascending: dir == "asc"})
+	}
+	return res, nil
+}
@@ -0,0 +1,213 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ToHTML renders a line of terminal output as HTML. ANSI colours and text attributes
+// become spans with term-* classes, all other escape sequences are removed.
+func ToHTML(line string) string {
+	var (
+		res   strings.Builder
+		state sgrState
+		open  bool
+	)
+	flush := func(text string) {
+		if text == "" {
+			return
+		}
+		res.WriteString(html.EscapeString(text))
+	}
+
+	for {
+		idx := strings.IndexByte(line, '\x1b')
+		if idx < 0 {
+			flush(line)
+			break
+		}
+		flush(line[:idx])
+		seq, params, final := parseEscape(line[idx:])
+		line = line[idx+len(seq):]
+		if final != 'm' {
+			continue
+		}
+
+		state.apply(params)
+		if open {
+			res.WriteString("</span>")
+			open = false
+		}
+		if tag := state.openTag(); tag != "" {
+			res.WriteString(tag)
+			open = true
+		}
+	}
+	if open {
+		res.WriteString("</span>")
+	}
+	return res.String()
+}
+
+// parseEscape returns the escape sequence at the start of s, its parameters and
+// its final byte if it is a control sequence.
+func parseEscape(s string) (seq, params string, final byte) {
+	if len(s) < 2 {
+		return s, "", 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return s[:i+1], s[2:i], s[i]
+			}
+		}
+		return s, "", 0
+	case ']':
+		// operating system commands end with BEL or ST
+		if end := strings.IndexAny(s[2:], "\a\x1b"); end >= 0 {
+			end += 2
+			if s[end] == '\x1b' && end+1 < len(s) && s[end+1] == '\\' {
+				return s[:end+2], "", 0
+			}
+			if s[end] == '\a' {
+				return s[:end+1], "", 0
+			}
+		}
+		return s, "", 0
+	}
+	return s[:2], "", 0
+}
+
+// sgrState is the set of text attributes a terminal applies to its output
+type sgrState struct {
+	bold, italic, underline bool
+	fg, bg                  string
+	fgStyle, bgStyle        string
+}
+
+func (s *sgrState) apply(params string) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil && codes[i] != "" {
+			continue
+		}
+		switch {
+		case code == 0:
+			*s = sgrState{}
+		case code == 1:
+			s.bold = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 22:
+			s.bold = false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code >= 30 && code <= 37, code >= 90 && code <= 97:
+			s.fg, s.fgStyle = fmt.Sprintf("term-fg%d", code), ""
+		case code == 39:
+			s.fg, s.fgStyle = "", ""
+		case code >= 40 && code <= 47, code >= 100 && code <= 107:
+			s.bg, s.bgStyle = fmt.Sprintf("term-bg%d", code), ""
+		case code == 49:
+			s.bg, s.bgStyle = "", ""
+		case code == 38 || code == 48:
+			class, style, n := extendedColor(codes[i+1:])
+			i += n
+			if code == 38 {
+				s.fg, s.fgStyle = strings.Replace(class, "term-x", "term-fgx", 1), style
+			} else {
+				s.bg, s.bgStyle = strings.Replace(class, "term-x", "term-bgx", 1), style
+			}
+		}
+	}
+}
+
+// extendedColor parses 256 colour (5;n) and true colour (2;r;g;b) parameters.
+// It returns either a class or a CSS colour, and the number of parameters consumed.
+func extendedColor(params []string) (class, style string, n int) {
+	if len(params) >= 2 && params[0] == "5" {
+		if c, err := strconv.Atoi(params[1]); err == nil && c >= 0 && c <= 255 {
+			return fmt.Sprintf("term-x%d", c), "", 2
+		}
+		return "", "", 2
+	}
+	if len(params) >= 4 && params[0] == "2" {
+		var rgb [3]int
+		for i := range rgb {
+			c, err := strconv.Atoi(params[i+1])
+			if err != nil || c < 0 || c > 255 {
+				return "", "", 4
+			}
+			rgb[i] = c
+		}
+		return "", fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), 4
+	}
+	return "", "", len(params)
+}
+
+func (s *sgrState) openTag() string {
+	var classes, styles []string
+	if s.fg != "" {
+		classes = append(classes, s.fg)
+	}
+	if s.bg != "" {
+		classes = append(classes, s.bg)
+	}
+	if s.bold {
+		classes = append(classes, "term-bold")
+	}
+	if s.italic {
+		classes = append(classes, "term-italic")
+	}
+	if s.underline {
+		classes = append(classes, "term-underline")
+	}
+	if s.fgStyle != "" {
+		styles = append(styles, "color:"+s.fgStyle)
+	}
+	if s.bgStyle != "" {
+		styles = append(styles, "background-color:"+s.bgStyle)
+	}
+	if len(classes) == 0 && len(styles) == 0 {
+		return ""
+	}
+
+	var res strings.Builder
+	res.WriteString("<span")
+	if len(classes) > 0 {
+		fmt.Fprintf(&res, ` class="%s"`, strings.Join(classes, " "))
+	}
+	if len(styles) > 0 {
+		fmt.Fprintf(&res, ` style="%s"`, strings.Join(styles, ";"))
+	}
+	res.WriteString(">")
+	return res.String()
+}
@@ -0,0 +1,187 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+)
+
+// DefaultSlice is the name of the slice unmarked log lines belong to
+const DefaultSlice = ""
+
+var markerExpr = regexp.MustCompile(`^\[([\w\.:/-]+)(?:\|(PHASE|DONE|FAIL|RESULT))?\](?: (.*))?$`)
+
+var sliceNameSanitizer = regexp.MustCompile(`[^\w\.:/-]+`)
+
+// SliceName turns an arbitrary string into a valid slice name
+func SliceName(s string) string {
+	return sliceNameSanitizer.ReplaceAllString(s, "-")
+}
+
+// Cutter turns the log output of an engine into slices. Engines mark up their
+// output using in-band markers at the start of a line:
+//
+//	[name|PHASE] description    starts a new phase of the engine
+//	[name] text                 is content of slice name - the first such line starts it
+//	[name|DONE]                 ends slice name successfully
+//	[name|FAIL] reason          ends slice name unsuccessfully
+//	[type|RESULT] payload desc  publishes a result of the engine
+//
+// All other lines are content of the DefaultSlice. Cutter keeps track of the
+// open slices and is not safe for concurrent use.
+type Cutter struct {
+	open []string
+}
+
+// New creates a new cutter
+func New() *Cutter {
+	return &Cutter{}
+}
+
+// Line cuts a single log line, which must not contain a line break.
+func (c *Cutter) Line(line string) []*v1.LogSliceEvent {
+	line = strings.TrimSuffix(line, "\r")
+	m := markerExpr.FindStringSubmatch(line)
+	if m == nil {
+		return []*v1.LogSliceEvent{{Name: DefaultSlice, Type: v1.LogSliceType_SLICE_CONTENT, Payload: line}}
+	}
+
+	name, payload := m[1], m[3]
+	switch m[2] {
+	case "PHASE":
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_PHASE, Payload: payload}}
+	case "RESULT":
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_RESULT, Payload: payload}}
+	case "DONE":
+		c.close(name)
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_DONE, Payload: payload}}
+	case "FAIL":
+		c.close(name)
+		return []*v1.LogSliceEvent{{Name: name, Type: v1.LogSliceType_SLICE_FAIL, Payload: payload}}
+	}
+
+	var res []*v1.LogSliceEvent
+	if !c.isOpen(name) {
+		c.open = append(c.open, name)
+		res = append(res, &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_START})
+	}
+	return append(res, &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_CONTENT, Payload: payload})
+}
+
+// Abandon ends all open slices in the order they were started. Call Abandon
+// once the log is complete.
+func (c *Cutter) Abandon() []*v1.LogSliceEvent {
+	res := make([]*v1.LogSliceEvent, 0, len(c.open))
+	for _, name := range c.open {
+		res = append(res, &v1.LogSliceEvent{Name: name, Type: v1.LogSliceType_SLICE_ABANDONED})
+	}
+	c.open = nil
+	return res
+}
+
+func (c *Cutter) isOpen(name string) bool {
+	for _, n := range c.open {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cutter) close(name string) {
+	for i, n := range c.open {
+		if n == name {
+			c.open = append(c.open[:i], c.open[i+1:]...)
+			return
+		}
+	}
+}
+
+// Slice reads the log from r until EOF and passes each slice event to send.
+// Slices which are still open at the end of the log are abandoned.
+func Slice(r io.Reader, send func(*v1.LogSliceEvent) error) error {
+	var (
+		c   = New()
+		in  = bufio.NewReader(r)
+		err error
+	)
+	for err == nil {
+		var line string
+		line, err = in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		for _, evt := range c.Line(strings.TrimSuffix(line, "\n")) {
+			if serr := send(evt); serr != nil {
+				return serr
+			}
+		}
+	}
+	for _, evt := range c.Abandon() {
+		if serr := send(evt); serr != nil {
+			return serr
+		}
+	}
+	return nil
+}
+
+// ParseResult turns a SLICE_RESULT event into an engine result. The slice name is the
+// result type. The payload is either a JSON object with payload, description and
+// channels fields, or the result payload followed by an optional description.
+func ParseResult(evt *v1.LogSliceEvent) (*v1.EngineResult, error) {
+	if evt.Type != v1.LogSliceType_SLICE_RESULT {
+		return nil, fmt.Errorf("%s is not a result", evt.Type)
+	}
+
+	res := &v1.EngineResult{Type: evt.Name}
+	body := strings.TrimSpace(evt.Payload)
+	if strings.HasPrefix(body, "{") {
+		var r struct {
+			Payload     string   `json:"payload"`
+			Description string   `json:"description"`
+			Channels    []string `json:"channels"`
+		}
+		if err := json.Unmarshal([]byte(body), &r); err != nil {
+			return nil, fmt.Errorf("cannot parse %s result: %w", evt.Name, err)
+		}
+		res.Payload, res.Description, res.Channels = r.Payload, r.Description, r.Channels
+	} else {
+		segs := strings.SplitN(body, " ", 2)
+		res.Payload = segs[0]
+		if len(segs) > 1 {
+			res.Description = strings.TrimSpace(segs[1])
+		}
+	}
+	if res.Payload == "" {
+		return nil, fmt.Errorf("%s result has no payload", evt.Name)
+	}
+	return res, nil
+}
@@ -0,0 +1,143 @@
+package logcutter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlice(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Input    string
+		Expected []string
+	}{
+		{
+			Name:     "unmarked",
+			Input:    "hello\nworld",
+			Expected: []string{"CONTENT  hello", "CONTENT  world"},
+		},
+		{
+			Name:  "slices",
+			Input: "[migrate|PHASE] migrating\n[statement.1] one\n[statement.1] two\n[statement.1|DONE]\n[statement.2] three\n[statement.2|FAIL] broken\n",
+			Expected: []string{
+				"PHASE migrate migrating",
+				"START statement.1 ",
+				"CONTENT statement.1 one",
+				"CONTENT statement.1 two",
+				"DONE statement.1 ",
+				"START statement.2 ",
+				"CONTENT statement.2 three",
+				"FAIL statement.2 broken",
+			},
+		},
+		{
+			Name:  "abandoned",
+			Input: "[a] one\n[b] two\n[a|DONE]\n[c] three\n",
+			Expected: []string{
+				"START a ",
+				"CONTENT a one",
+				"START b ",
+				"CONTENT b two",
+				"DONE a ",
+				"START c ",
+				"CONTENT c three",
+				"ABANDONED b ",
+				"ABANDONED c ",
+			},
+		},
+		{
+			Name:     "result",
+			Input:    "[url|RESULT] https://example.com the report\r\n",
+			Expected: []string{"RESULT url https://example.com the report"},
+		},
+		{
+			Name:     "not a marker",
+			Input:    "[not a marker] text\n[a|UNKNOWN] text\n",
+			Expected: []string{"CONTENT  [not a marker] text", "CONTENT  [a|UNKNOWN] text"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var act []string
+			err := Slice(strings.NewReader(test.Input), func(evt *v1.LogSliceEvent) error {
+				act = append(act, fmt.Sprintf("%s %s %s", strings.TrimPrefix(evt.Type.String(), "SLICE_"), evt.Name, evt.Payload))
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.Expected, act)
+		})
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Payload  string
+		Expected *v1.EngineResult
+		Error    bool
+	}{
+		{Name: "payload only", Payload: "42", Expected: &v1.EngineResult{Type: "rows", Payload: "42"}},
+		{Name: "description", Payload: "42 rows were migrated", Expected: &v1.EngineResult{Type: "rows", Payload: "42", Description: "rows were migrated"}},
+		{
+			Name:     "json",
+			Payload:  `{"payload":"42","description":"rows were migrated","channels":["slack"]}`,
+			Expected: &v1.EngineResult{Type: "rows", Payload: "42", Description: "rows were migrated", Channels: []string{"slack"}},
+		},
+		{Name: "invalid json", Payload: `{"payload":`, Error: true},
+		{Name: "empty", Payload: " ", Error: true},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			res, err := ParseResult(&v1.LogSliceEvent{Name: "rows", Type: v1.LogSliceType_SLICE_RESULT, Payload: test.Payload})
+			if test.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.Expected, res)
+		})
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{"plain <b>text</b>", "plain &lt;b&gt;text&lt;/b&gt;"},
+		{"\x1b[31mred\x1b[0m plain", `<span class="term-fg31">red</span> plain`},
+		{"\x1b[1;32mbold green\x1b[22m green\x1b[m", `<span class="term-fg32 term-bold">bold green</span><span class="term-fg32"> green</span>`},
+		{"\x1b[38;5;208morange\x1b[48;2;0;0;255m on blue", `<span class="term-fgx208">orange</span><span class="term-fgx208" style="background-color:#0000ff"> on blue</span>`},
+		{"\x1b[2Kcleared\x1b]0;title\a line", "cleared line"},
+		{"dangling \x1b[", "dangling "},
+	}
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			assert.Equal(t, test.Expected, ToHTML(test.Input))
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package sqllex
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strings"
+)
+
+// Kind is the kind of a Token
+type Kind int
+
+const (
+	// Text is anything not lexed as another kind: keywords, names, numbers, operators and white space
+	Text Kind = iota
+	// Placeholder is a ? bind parameter
+	Placeholder
+	// QuestionOperator is one of the JSONB operators ?| and ?&, or ?? standing for the ? operator
+	QuestionOperator
+	// String is a string literal in single quotes, a dollar-quoted or an alternatively quoted string
+	String
+	// QuotedIdent is an identifier in double quotes, backticks or square brackets. MySQL takes
+	// double quotes for a string.
+	QuotedIdent
+	// Comment is a line comment without its line break or a block comment
+	Comment
+	// Semicolon ends a statement
+	Semicolon
+)
+
+// Token is a piece of SQL
+type Token struct {
+	Kind Kind
+	// Text is the token as it is written
+	Text string
+	// Pos is the byte offset of the token in the SQL
+	Pos int
+}
+
+// Syntax describes the lexical rules a database adds to the ones of standard SQL, which are
+// strings in single quotes, identifiers in double quotes, -- and /* */ comments
+type Syntax struct {
+	// BackslashEscapes lets a backslash escape the next character of a quoted string or identifier
+	BackslashEscapes bool
+	// EscapeStrings lets a backslash escape the next character of E'...' strings
+	EscapeStrings bool
+	// Backticks quotes identifiers in backticks
+	Backticks bool
+	// Brackets quotes identifiers in square brackets
+	Brackets bool
+	// DollarQuotes supports dollar-quoted strings like $$...$$ or $tag$...$tag$
+	DollarQuotes bool
+	// AlternativeQuotes supports strings like q'[...]' quoted by a delimiter of choice
+	AlternativeQuotes bool
+	// HashComments starts line comments by #
+	HashComments bool
+	// DashCommentSpace requires -- to be followed by white space to start a comment
+	DashCommentSpace bool
+	// NestedComments lets /* */ comments nest
+	NestedComments bool
+	// QuestionOperators lexes ?|, ?& and ?? as JSONB operators instead of placeholders
+	QuestionOperators bool
+}
+
+var (
+	// Generic lexes SQL of unknown databases, tolerating the quoting of most of them
+	Generic = Syntax{BackslashEscapes: true, Backticks: true, DollarQuotes: true}
+	// PostgreSQL lexes the SQL of PostgreSQL
+	PostgreSQL = Syntax{EscapeStrings: true, DollarQuotes: true, NestedComments: true, QuestionOperators: true}
+	// MySQL lexes the SQL of MySQL and MariaDB
+	MySQL = Syntax{BackslashEscapes: true, Backticks: true, HashComments: true, DashCommentSpace: true}
+	// SQLite lexes the SQL of SQLite
+	SQLite = Syntax{Backticks: true, Brackets: true}
+	// SQLServer lexes the SQL of SQL Server
+	SQLServer = Syntax{Brackets: true, NestedComments: true}
+	// Oracle lexes the SQL of Oracle
+	Oracle = Syntax{AlternativeQuotes: true}
+)
+
+// Lex splits sql into tokens. Unterminated strings, quoted identifiers and comments run to the end of sql.
+func Lex(sql string, syntax Syntax) []Token {
+	var tokens []Token
+	text := 0
+	for i := 0; i < len(sql); {
+		kind, end := syntax.scan(sql, i)
+		if kind != Text {
+			if text < i {
+				tokens = append(tokens, Token{Text, sql[text:i], text})
+			}
+			tokens = append(tokens, Token{kind, sql[i:end], i})
+			text = end
+		}
+		i = end
+	}
+	if text < len(sql) {
+		tokens = append(tokens, Token{Text, sql[text:], text})
+	}
+	return tokens
+}
+
+// Split splits a script into its statements at the semicolons outside of strings, quoted identifiers and
+// comments. The statements are trimmed of white space and their semicolon, statements of nothing but comments
+// are dropped. Semicolons within the body of a routine end a statement unless the body is dollar-quoted.
+func Split(script string, syntax Syntax) []string {
+	var (
+		statements []string
+		start      int
+		empty      = true
+	)
+	add := func(end int) {
+		if !empty {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+	}
+	for _, t := range Lex(script, syntax) {
+		switch t.Kind {
+		case Semicolon:
+			add(t.Pos)
+			start, empty = t.Pos+1, true
+		case Comment:
+		case Text:
+			if strings.TrimSpace(t.Text) != "" {
+				empty = false
+			}
+		default:
+			empty = false
+		}
+	}
+	add(len(script))
+	return statements
+}
+
+// scan returns the kind and the end of the token at i, which is Text up to i+1 if no other kind starts at i
+func (s Syntax) scan(sql string, i int) (Kind, int) {
+	next := func(n int) byte {
+		if i+n < len(sql) {
+			return sql[i+n]
+		}
+		return 0
+	}
+	switch c := sql[i]; {
+	case c == '?':
+		if s.QuestionOperators {
+			switch next(1) {
+			case '?':
+				return QuestionOperator, i + 2
+			case '|', '&':
+				// ?|| and ?&& are a placeholder followed by the || and && operators
+				if next(2) != next(1) {
+					return QuestionOperator, i + 2
+				}
+			}
+		}
+		return Placeholder, i + 1
+	case c == ';':
+		return Semicolon, i + 1
+	case c == '\'':
+		escape := s.BackslashEscapes ||
+			s.EscapeStrings && i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !isIdent(sql[i-2]))
+		return String, quoted(sql, i, '\'', escape)
+	case c == '"':
+		return QuotedIdent, quoted(sql, i, '"', s.BackslashEscapes)
+	case c == '`' && s.Backticks:
+		return QuotedIdent, quoted(sql, i, '`', false)
+	case c == '[' && s.Brackets:
+		return QuotedIdent, quoted(sql, i, ']', false)
+	case c == '-' && next(1) == '-' && (!s.DashCommentSpace || isSpace(next(2)) || i+2 == len(sql)),
+		c == '#' && s.HashComments:
+		if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+			return Comment, i + end
+		}
+		return Comment, len(sql)
+	case c == '/' && next(1) == '*':
+		return Comment, s.blockComment(sql, i)
+	case c == '$' && s.DollarQuotes && (i == 0 || !isIdent(sql[i-1])):
+		if end, ok := dollarQuoted(sql, i); ok {
+			return String, end
+		}
+	case (c == 'q' || c == 'Q') && s.AlternativeQuotes && next(1) == '\'' && (i == 0 || !isIdent(sql[i-1])):
+		if end, ok := alternativeQuoted(sql, i); ok {
+			return String, end
+		}
+	}
+	return Text, i + 1
+}
+
+// quoted returns the end of the string or identifier quoted at i, closed by close which is escaped by doubling it
+func quoted(sql string, i int, close byte, backslashEscapes bool) int {
+	for j := i + 1; j < len(sql); j++ {
+		switch {
+		case backslashEscapes && sql[j] == '\\':
+			j++
+		case sql[j] == close:
+			if j+1 < len(sql) && sql[j+1] == close {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(sql)
+}
+
+// blockComment returns the end of the /* */ comment at i
+func (s Syntax) blockComment(sql string, i int) int {
+	depth := 0
+	for j := i; j+1 < len(sql); j++ {
+		switch {
+		case sql[j] == '/' && sql[j+1] == '*' && (depth == 0 || s.NestedComments):
+			depth++
+			j++
+		case sql[j] == '*' && sql[j+1] == '/':
+			if depth--; depth == 0 {
+				return j + 2
+			}
+			j++
+		}
+	}
+	return len(sql)
+}
+
+// dollarQuoted returns the end of the dollar-quoted string at i, if the $ starts one
+func dollarQuoted(sql string, i int) (int, bool) {
+	j := i + 1
+	for j < len(sql) && isIdent(sql[j]) && sql[j] != '$' && !(j == i+1 && isDigit(sql[j])) {
+		j++
+	}
+	if j == len(sql) || sql[j] != '$' {
+		return 0, false
+	}
+	tag := sql[i : j+1]
+	if end := strings.Index(sql[j+1:], tag); end >= 0 {
+		return j + 1 + end + len(tag), true
+	}
+	return len(sql), true
+}
+
+// alternativeQuoted returns the end of the q'...' string at i, whose delimiter follows the quote
+func alternativeQuoted(sql string, i int) (int, bool) {
+	if i+2 >= len(sql) || isSpace(sql[i+2]) {
+		return 0, false
+	}
+	delimiter := sql[i+2]
+	if j := strings.IndexByte("([{<", delimiter); j >= 0 {
+		delimiter = ")]}>"[j]
+	}
+	if end := strings.Index(sql[i+3:], string([]byte{delimiter, '\''})); end >= 0 {
+		return i + 3 + end + 2, true
+	}
+	return len(sql), true
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
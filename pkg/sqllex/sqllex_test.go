@@ -0,0 +1,102 @@
+package sqllex
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// placeholders returns the positions of the placeholders of sql
+func placeholders(sql string, syntax Syntax) []int {
+	var res []int
+	for _, t := range Lex(sql, syntax) {
+		if t.Kind == Placeholder {
+			res = append(res, t.Pos)
+		}
+	}
+	return res
+}
+
+func TestLex(t *testing.T) {
+	assert.EqualValues(t, []Token{
+		{Text, "SELECT a, ", 0},
+		{String, "'x?'", 10},
+		{Text, " FROM t WHERE id=", 14},
+		{Placeholder, "?", 31},
+		{Semicolon, ";", 32},
+		{Comment, "-- done?", 33},
+	}, Lex("SELECT a, 'x?' FROM t WHERE id=?;-- done?", Generic))
+	assert.Nil(t, Lex("", Generic))
+}
+
+func TestLexPlaceholders(t *testing.T) {
+	cases := []struct {
+		sql      string
+		syntax   Syntax
+		expected []int
+	}{
+		{`SELECT "a?" FROM t WHERE b=?`, PostgreSQL, []int{27}},
+		{"SELECT `a?` FROM t WHERE b=?", MySQL, []int{27}},
+		{"SELECT [a?] FROM t WHERE b=?", SQLServer, []int{27}},
+		{"SELECT a[?] FROM t", PostgreSQL, []int{9}},
+		{"SELECT 'it''s?', ?", Generic, []int{17}},
+		{`SELECT 'a\'?', ?`, MySQL, []int{15}},
+		{`SELECT 'a\', ?`, PostgreSQL, []int{13}},
+		{`SELECT E'a\'?', ?`, PostgreSQL, []int{16}},
+		{"SELECT 1 -- ?\n, ?", Generic, []int{16}},
+		{"SELECT 1 /* ? */, ?", Generic, []int{18}},
+		{"SELECT 1 /* /* ? */ ? */, ?", PostgreSQL, []int{26}},
+		{"SELECT 1 /* /* ? */ ? */", SQLServer, nil},
+		{"SELECT 1 /* /* ? */ ?", Oracle, []int{20}},
+		{"SELECT 1 # ?\n, ?", MySQL, []int{15}},
+		{"SELECT 1 # ?", PostgreSQL, []int{11}},
+		{"SELECT 5--?", MySQL, []int{10}},
+		{"SELECT $$a?$$, $tag$ $$?$$ $tag$, ?", PostgreSQL, []int{34}},
+		{"SELECT a$b$, ?, $1", PostgreSQL, []int{13}},
+		{"SELECT q'[it's?]', ?", Oracle, []int{19}},
+		{"SELECT data ?| array[?], data ?& array[?], data ?? ?, ?||'x'", PostgreSQL, []int{21, 39, 51, 54}},
+		{"SELECT a ?| ?", MySQL, []int{9, 12}},
+		{"SELECT 'unterminated ?", Generic, nil},
+	}
+	for _, c := range cases {
+		assert.EqualValues(t, c.expected, placeholders(c.sql, c.syntax), c.sql)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	script := `-- the schema
+CREATE TABLE "a;b" (c text DEFAULT ';');
+/* seed; */ INSERT INTO "a;b" VALUES ('x;y');;
+CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;
+-- trailing comment;
+`
+	assert.EqualValues(t, []string{
+		`-- the schema
+CREATE TABLE "a;b" (c text DEFAULT ';')`,
+		`/* seed; */ INSERT INTO "a;b" VALUES ('x;y')`,
+		"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql",
+	}, Split(script, PostgreSQL))
+
+	assert.EqualValues(t, []string{"SELECT 1", "SELECT `;`"}, Split("SELECT 1;SELECT `;`", MySQL))
+	assert.Nil(t, Split(" ; -- nothing\n", Generic))
+}
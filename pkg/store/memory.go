@@ -0,0 +1,218 @@
+package store
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewInMemoryEngineStore creates a new in-memory engine store
+func NewInMemoryEngineStore() *InMemoryEngineStore {
+	return &InMemoryEngineStore{
+		engines: make(map[string]*v1.EngineStatus),
+	}
+}
+
+// InMemoryEngineStore implements an in-memory engine store. Its content is lost
+// when the process ends.
+type InMemoryEngineStore struct {
+	mu      sync.RWMutex
+	engines map[string]*v1.EngineStatus
+	names   []string
+}
+
+var _ Engines = &InMemoryEngineStore{}
+
+// Store stores the engine status
+func (s *InMemoryEngineStore) Store(ctx context.Context, status *v1.EngineStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.engines[status.Name]; !exists {
+		s.names = append(s.names, status.Name)
+	}
+	s.engines[status.Name] = proto.Clone(status).(*v1.EngineStatus)
+	return nil
+}
+
+// Get retrieves a particular engine by its name
+func (s *InMemoryEngineStore) Get(ctx context.Context, name string) (*v1.EngineStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status, ok := s.engines[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(status).(*v1.EngineStatus), nil
+}
+
+// Find searches for engines. Without order, engines are returned in the order they were first stored.
+func (s *InMemoryEngineStore) Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.EngineStatus, total int, err error) {
+	s.mu.RLock()
+	var res []*v1.EngineStatus
+	for _, name := range s.names {
+		status := s.engines[name]
+		if !filterexpr.MatchesFilter(status, filter) {
+			continue
+		}
+		res = append(res, proto.Clone(status).(*v1.EngineStatus))
+	}
+	s.mu.RUnlock()
+
+	if len(order) > 0 {
+		sort.SliceStable(res, func(i, j int) bool {
+			for _, o := range order {
+				c := compareField(res[i], res[j], o.Field)
+				if c == 0 {
+					continue
+				}
+				if o.Ascending {
+					return c < 0
+				}
+				return c > 0
+			}
+			return false
+		})
+	}
+
+	total = len(res)
+	if start > total {
+		start = total
+	}
+	end := total
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return res[start:end], total, nil
+}
+
+// compareField compares a field of two engines. Absent values sort first.
+func compareField(a, b *v1.EngineStatus, field string) int {
+	switch field {
+	case "created":
+		return compareTimestamps(a.GetMetadata().GetCreated(), b.GetMetadata().GetCreated())
+	case "finished":
+		return compareTimestamps(a.GetMetadata().GetFinished(), b.GetMetadata().GetFinished())
+	}
+	av, _ := filterexpr.FieldValue(a, field)
+	bv, _ := filterexpr.FieldValue(b, field)
+	return strings.Compare(av, bv)
+}
+
+func compareTimestamps(a, b *timestamppb.Timestamp) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	at, bt := a.AsTime(), b.AsTime()
+	switch {
+	case at.Before(bt):
+		return -1
+	case at.After(bt):
+		return 1
+	}
+	return 0
+}
+
+// NewInMemoryNumberGroup creates a new in-memory number group
+func NewInMemoryNumberGroup() *InMemoryNumberGroup {
+	return &InMemoryNumberGroup{
+		groups: make(map[string]int),
+	}
+}
+
+// InMemoryNumberGroup implements an in-memory number group
+type InMemoryNumberGroup struct {
+	mu     sync.Mutex
+	groups map[string]int
+}
+
+var _ NumberGroup = &InMemoryNumberGroup{}
+
+// Latest returns the latest number of a group
+func (s *InMemoryNumberGroup) Latest(ctx context.Context, group string) (nr int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nr, ok := s.groups[group]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return nr, nil
+}
+
+// Next returns the next number of a group
+func (s *InMemoryNumberGroup) Next(ctx context.Context, group string) (nr int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[group]++
+	return s.groups[group], nil
+}
+
+// NewInMemoryEngineSpecStore creates a new in-memory engine spec store
+func NewInMemoryEngineSpecStore() *InMemoryEngineSpecStore {
+	return &InMemoryEngineSpecStore{
+		specs: make(map[string]*EngineSpec),
+	}
+}
+
+// InMemoryEngineSpecStore implements an in-memory engine spec store
+type InMemoryEngineSpecStore struct {
+	mu    sync.RWMutex
+	specs map[string]*EngineSpec
+}
+
+var _ EngineSpecs = &InMemoryEngineSpecStore{}
+
+// Store stores the spec of an engine
+func (s *InMemoryEngineSpecStore) Store(ctx context.Context, name string, spec *EngineSpec) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.specs[name] = &EngineSpec{
+		EngineYAML: append([]byte(nil), spec.EngineYAML...),
+		Sideload:   append([]byte(nil), spec.Sideload...),
+	}
+	return nil
+}
+
+// Get retrieves the spec of an engine
+func (s *InMemoryEngineSpecStore) Get(ctx context.Context, name string) (*EngineSpec, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	spec, ok := s.specs[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &EngineSpec{
+		EngineYAML: append([]byte(nil), spec.EngineYAML...),
+		Sideload:   append([]byte(nil), spec.Sideload...),
+	}, nil
+}
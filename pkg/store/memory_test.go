@@ -0,0 +1,106 @@
+package store
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestInMemoryEngineStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemoryEngineStore()
+	now := time.Now()
+	for i, name := range []string{"b.1", "a.1", "c.1"} {
+		err := s.Store(ctx, &v1.EngineStatus{
+			Name:     name,
+			Phase:    v1.EnginePhase_PHASE_DONE,
+			Metadata: &v1.EngineMetadata{Owner: "dba", Created: timestamppb.New(now.Add(time.Duration(i) * time.Minute))},
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, s.Store(ctx, &v1.EngineStatus{Name: "a.1", Phase: v1.EnginePhase_PHASE_RUNNING, Metadata: &v1.EngineMetadata{Owner: "dev"}}))
+
+	st, err := s.Get(ctx, "a.1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, v1.EnginePhase_PHASE_RUNNING, st.Phase)
+	_, err = s.Get(ctx, "d.1")
+	assert.EqualValues(t, ErrNotFound, err)
+
+	slice, total, err := s.Find(ctx, nil, nil, 0, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.EqualValues(t, []string{"b.1", "a.1", "c.1"}, names(slice))
+
+	slice, total, err = s.Find(ctx, nil, []*v1.OrderExpression{{Field: "name", Ascending: true}}, 1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.EqualValues(t, []string{"b.1"}, names(slice))
+
+	slice, total, err = s.Find(ctx, []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "owner", Value: "dba"}}}},
+		[]*v1.OrderExpression{{Field: "created", Ascending: false}}, 0, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.EqualValues(t, []string{"c.1", "b.1"}, names(slice))
+}
+
+func TestInMemoryNumberGroup(t *testing.T) {
+	ctx := context.Background()
+	ng := NewInMemoryNumberGroup()
+	_, err := ng.Latest(ctx, "migrate")
+	assert.EqualValues(t, ErrNotFound, err)
+	for i := 1; i <= 3; i++ {
+		nr, err := ng.Next(ctx, "migrate")
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, nr)
+	}
+	nr, err := ng.Latest(ctx, "migrate")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, nr)
+}
+
+func TestInMemoryEngineSpecStore(t *testing.T) {
+	ctx := context.Background()
+	specs := NewInMemoryEngineSpecStore()
+	_, err := specs.Get(ctx, "migrate.1")
+	assert.EqualValues(t, ErrNotFound, err)
+
+	yaml := []byte("driver: postgres")
+	assert.NoError(t, specs.Store(ctx, "migrate.1", &EngineSpec{EngineYAML: yaml}))
+	yaml[0] = 'X'
+	spec, err := specs.Get(ctx, "migrate.1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "driver: postgres", string(spec.EngineYAML))
+	assert.Empty(t, spec.Sideload)
+}
+
+func names(slice []*v1.EngineStatus) []string {
+	res := make([]string, 0, len(slice))
+	for _, s := range slice {
+		res = append(res, s.Name)
+	}
+	return res
+}
@@ -0,0 +1,301 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+	"github.com/bhojpur/sql/pkg/store"
+	"github.com/lib/pq"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EngineStore stores engine status in a PostgreSQL database
+type EngineStore struct {
+	DB *sql.DB
+}
+
+var _ store.Engines = &EngineStore{}
+
+// NewEngineStore creates a new PostgreSQL engine store. The database schema must
+// have been brought up to date using Migrate.
+func NewEngineStore(db *sql.DB) *EngineStore {
+	return &EngineStore{DB: db}
+}
+
+// statusColumns are the engine_status columns in the order scanStatus expects them
+var statusColumns = []string{
+	"name", "owner",
+	"repo_host", "repo_owner", "repo_repo", "repo_ref", "repo_revision",
+	"trigger", "engine_spec_name", "phase",
+	"success", "failure_count", "can_replay", "did_execute", "wait_until",
+	"details", "created", "finished",
+}
+
+// Store stores the engine status, its annotations and results
+func (s *EngineStore) Store(ctx context.Context, status *v1.EngineStatus) error {
+	md := status.Metadata
+	if md == nil {
+		md = &v1.EngineMetadata{}
+	}
+	repo := md.Repository
+	if repo == nil {
+		repo = &v1.Repository{}
+	}
+	cond := status.Conditions
+	if cond == nil {
+		cond = &v1.EngineConditions{}
+	}
+	created := time.Now()
+	if md.Created != nil {
+		created = md.Created.AsTime()
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO engine_status (`+strings.Join(statusColumns, ", ")+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (name) DO UPDATE SET
+			owner = EXCLUDED.owner,
+			repo_host = EXCLUDED.repo_host,
+			repo_owner = EXCLUDED.repo_owner,
+			repo_repo = EXCLUDED.repo_repo,
+			repo_ref = EXCLUDED.repo_ref,
+			repo_revision = EXCLUDED.repo_revision,
+			trigger = EXCLUDED.trigger,
+			engine_spec_name = EXCLUDED.engine_spec_name,
+			phase = EXCLUDED.phase,
+			success = EXCLUDED.success,
+			failure_count = EXCLUDED.failure_count,
+			can_replay = EXCLUDED.can_replay,
+			did_execute = EXCLUDED.did_execute,
+			wait_until = EXCLUDED.wait_until,
+			details = EXCLUDED.details,
+			created = EXCLUDED.created,
+			finished = EXCLUDED.finished`,
+		status.Name, md.Owner,
+		repo.Host, repo.Owner, repo.Repo, repo.Ref, repo.Revision,
+		filterexpr.TriggerValue(md.Trigger), md.EngineSpecName, filterexpr.PhaseValue(status.Phase),
+		cond.Success, cond.FailureCount, cond.CanReplay, cond.DidExecute, nullTime(cond.WaitUntil),
+		status.Details, created, nullTime(md.Finished),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot store engine %s: %w", status.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM engine_annotation WHERE engine_name = $1", status.Name); err != nil {
+		return err
+	}
+	for i, a := range md.Annotations {
+		_, err := tx.ExecContext(ctx, "INSERT INTO engine_annotation (engine_name, position, key, value) VALUES ($1, $2, $3, $4)",
+			status.Name, i, a.Key, a.Value)
+		if err != nil {
+			return fmt.Errorf("cannot store annotation %s of engine %s: %w", a.Key, status.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM engine_result WHERE engine_name = $1", status.Name); err != nil {
+		return err
+	}
+	for i, r := range status.Results {
+		_, err := tx.ExecContext(ctx, "INSERT INTO engine_result (engine_name, position, type, payload, description, channels) VALUES ($1, $2, $3, $4, $5, $6)",
+			status.Name, i, r.Type, r.Payload, r.Description, pq.Array(r.Channels))
+		if err != nil {
+			return fmt.Errorf("cannot store result of engine %s: %w", status.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a particular engine by its name
+func (s *EngineStore) Get(ctx context.Context, name string) (*v1.EngineStatus, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT "+strings.Join(statusColumns, ", ")+" FROM engine_status WHERE name = $1", name)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.scanAll(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return res[0], nil
+}
+
+// Find searches for engines. Without order, engines are returned in the order they were created.
+func (s *EngineStore) Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.EngineStatus, total int, err error) {
+	cq, err := countQuery(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	query, args, err := cq.ToSQL()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.DB.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	if start >= total {
+		return nil, total, nil
+	}
+
+	fq, err := findQuery(filter, order, start, limit, total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query, args, err = fq.ToSQL()
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	slice, err = s.scanAll(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slice, total, nil
+}
+
+// scanAll reads all engine_status rows and loads their annotations and results
+func (s *EngineStore) scanAll(ctx context.Context, rows *sql.Rows) ([]*v1.EngineStatus, error) {
+	var (
+		res   []*v1.EngineStatus
+		names []string
+		idx   = make(map[string]*v1.EngineStatus)
+	)
+	for rows.Next() {
+		status, err := scanStatus(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		res = append(res, status)
+		names = append(names, status.Name)
+		idx[status.Name] = status
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	rows, err := s.DB.QueryContext(ctx, "SELECT engine_name, key, value FROM engine_annotation WHERE engine_name = ANY($1) ORDER BY engine_name, position", pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name string
+			a    v1.Annotation
+		)
+		if err := rows.Scan(&name, &a.Key, &a.Value); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		md := idx[name].Metadata
+		md.Annotations = append(md.Annotations, &a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.DB.QueryContext(ctx, "SELECT engine_name, type, payload, description, channels FROM engine_result WHERE engine_name = ANY($1) ORDER BY engine_name, position", pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name string
+			r    v1.EngineResult
+		)
+		if err := rows.Scan(&name, &r.Type, &r.Payload, &r.Description, pq.Array(&r.Channels)); err != nil {
+			return nil, err
+		}
+		status := idx[name]
+		status.Results = append(status.Results, &r)
+	}
+	return res, rows.Err()
+}
+
+func scanStatus(rows *sql.Rows) (*v1.EngineStatus, error) {
+	var (
+		status = &v1.EngineStatus{
+			Metadata:   &v1.EngineMetadata{Repository: &v1.Repository{}},
+			Conditions: &v1.EngineConditions{},
+		}
+		md                  = status.Metadata
+		repo                = md.Repository
+		cond                = status.Conditions
+		trigger, phase      string
+		created             time.Time
+		waitUntil, finished sql.NullTime
+	)
+	err := rows.Scan(
+		&status.Name, &md.Owner,
+		&repo.Host, &repo.Owner, &repo.Repo, &repo.Ref, &repo.Revision,
+		&trigger, &md.EngineSpecName, &phase,
+		&cond.Success, &cond.FailureCount, &cond.CanReplay, &cond.DidExecute, &waitUntil,
+		&status.Details, &created, &finished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	md.Trigger = v1.EngineTrigger(v1.EngineTrigger_value["TRIGGER_"+strings.ToUpper(trigger)])
+	status.Phase = v1.EnginePhase(v1.EnginePhase_value["PHASE_"+strings.ToUpper(phase)])
+	md.Created = timestamppb.New(created)
+	if finished.Valid {
+		md.Finished = timestamppb.New(finished.Time)
+	}
+	if waitUntil.Valid {
+		cond.WaitUntil = timestamppb.New(waitUntil.Time)
+	}
+	if repo.Host == "" && repo.Owner == "" && repo.Repo == "" && repo.Ref == "" && repo.Revision == "" {
+		md.Repository = nil
+	}
+	return status, nil
+}
+
+func nullTime(ts *timestamppb.Timestamp) sql.NullTime {
+	if ts == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: ts.AsTime(), Valid: true}
+}
@@ -0,0 +1,223 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/builder"
+	"github.com/bhojpur/sql/pkg/filterexpr"
+)
+
+// filterColumns maps the filter fields to engine_status columns. Only fields
+// listed here can make it into a query.
+var filterColumns = map[string]string{
+	"name":          "name",
+	"owner":         "owner",
+	"phase":         "phase",
+	"trigger":       "trigger",
+	"success":       "success",
+	"spec":          "engine_spec_name",
+	"repo.host":     "repo_host",
+	"repo.owner":    "repo_owner",
+	"repo.repo":     "repo_repo",
+	"repo.ref":      "repo_ref",
+	"repo.revision": "repo_revision",
+}
+
+// orderColumns maps the order fields to engine_status columns
+var orderColumns = map[string]string{
+	"created":  "created",
+	"finished": "finished",
+}
+
+func init() {
+	for k, v := range filterColumns {
+		orderColumns[k] = v
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards using PostgreSQL's default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterCond translates a filter into a condition. Terms of an expression are
+// OR'ed, the expressions themselves are AND'ed.
+func filterCond(filter []*v1.FilterExpression) (builder.Cond, error) {
+	exprs := make([]builder.Cond, 0, len(filter))
+	for _, expr := range filter {
+		terms := make([]builder.Cond, 0, len(expr.Terms))
+		for _, term := range expr.Terms {
+			c, err := termCond(term)
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, c)
+		}
+		if len(terms) == 1 {
+			exprs = append(exprs, terms[0])
+			continue
+		}
+		exprs = append(exprs, builder.Or(terms...))
+	}
+	return builder.And(exprs...), nil
+}
+
+func termCond(term *v1.FilterTerm) (res builder.Cond, err error) {
+	if strings.HasPrefix(term.Field, filterexpr.AnnotationPrefix) {
+		res, err = annotationCond(strings.TrimPrefix(term.Field, filterexpr.AnnotationPrefix), term)
+	} else {
+		res, err = columnCond(term)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if term.Negate {
+		res = builder.Not{res}
+	}
+	return res, nil
+}
+
+func columnCond(term *v1.FilterTerm) (builder.Cond, error) {
+	col, ok := filterColumns[term.Field]
+	if !ok {
+		return nil, fmt.Errorf("cannot filter by %q", term.Field)
+	}
+
+	if col == "success" {
+		// the column is never NULL, so it cannot tell engines without conditions apart
+		// the way the in-memory store does - EXISTS is refused instead of always matching
+		if term.Operation != v1.FilterOp_OP_EQUALS {
+			return nil, fmt.Errorf("success supports only %s", v1.FilterOp_OP_EQUALS)
+		}
+		v, err := strconv.ParseBool(term.Value)
+		if err != nil {
+			return nil, fmt.Errorf("success must be true or false: %w", err)
+		}
+		return builder.Eq{col: v}, nil
+	}
+
+	switch term.Operation {
+	case v1.FilterOp_OP_EXISTS:
+		// absent values are stored as empty strings
+		return builder.Neq{col: ""}, nil
+	case v1.FilterOp_OP_EQUALS:
+		return builder.Eq{col: term.Value}, nil
+	}
+	pattern, err := likePattern(term)
+	if err != nil {
+		return nil, err
+	}
+	return builder.Like{col, pattern}, nil
+}
+
+func annotationCond(key string, term *v1.FilterTerm) (builder.Cond, error) {
+	if key == "" {
+		return nil, fmt.Errorf("cannot filter by %q", term.Field)
+	}
+
+	cond := builder.Eq{"key": key}
+	var valueCond builder.Cond
+	switch term.Operation {
+	case v1.FilterOp_OP_EXISTS:
+	case v1.FilterOp_OP_EQUALS:
+		valueCond = builder.Eq{"value": term.Value}
+	default:
+		pattern, err := likePattern(term)
+		if err != nil {
+			return nil, err
+		}
+		valueCond = builder.Like{"value", pattern}
+	}
+	return builder.In("name", builder.Select("engine_name").From("engine_annotation").Where(cond.And(valueCond))), nil
+}
+
+func likePattern(term *v1.FilterTerm) (string, error) {
+	v := likeEscaper.Replace(term.Value)
+	switch term.Operation {
+	case v1.FilterOp_OP_STARTS_WITH:
+		return v + "%", nil
+	case v1.FilterOp_OP_ENDS_WITH:
+		return "%" + v, nil
+	case v1.FilterOp_OP_CONTAINS:
+		return "%" + v + "%", nil
+	}
+	return "", fmt.Errorf("unsupported filter operation %s", term.Operation)
+}
+
+// orderBy translates order expressions into an ORDER BY clause. The engine name is
+// always added as last criterion so that pages are stable.
+func orderBy(order []*v1.OrderExpression) (string, error) {
+	if len(order) == 0 {
+		return "created ASC, name ASC", nil
+	}
+
+	var (
+		res      = make([]string, 0, len(order)+1)
+		seenName bool
+	)
+	for _, o := range order {
+		col, ok := orderColumns[o.Field]
+		if !ok {
+			return "", fmt.Errorf("cannot order by %q", o.Field)
+		}
+		dir := "DESC"
+		if o.Ascending {
+			dir = "ASC"
+		}
+		res = append(res, col+" "+dir)
+		seenName = seenName || col == "name"
+	}
+	if !seenName {
+		res = append(res, "name ASC")
+	}
+	return strings.Join(res, ", "), nil
+}
+
+// countQuery produces a query counting all engines matching the filter
+func countQuery(filter []*v1.FilterExpression) (*builder.Builder, error) {
+	cond, err := filterCond(filter)
+	if err != nil {
+		return nil, err
+	}
+	return builder.Postgres().Select("COUNT(*)").From("engine_status").Where(cond), nil
+}
+
+// findQuery produces a query for a page of engines matching the filter. PostgreSQL accepts
+// an OFFSET without LIMIT, but the builder only writes one along with a LIMIT, which is why
+// callers pass the total number of matches to page up to the last one.
+func findQuery(filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit, total int) (*builder.Builder, error) {
+	cond, err := filterCond(filter)
+	if err != nil {
+		return nil, err
+	}
+	ob, err := orderBy(order)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || start+limit > total {
+		limit = total - start
+	}
+	return builder.Postgres().Select(statusColumns...).From("engine_status").
+		Where(cond).OrderBy(ob).Limit(limit, start), nil
+}
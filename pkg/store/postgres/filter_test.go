@@ -0,0 +1,137 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindQuery(t *testing.T) {
+	const cols = "SELECT name,owner,repo_host,repo_owner,repo_repo,repo_ref,repo_revision,trigger,engine_spec_name,phase,success,failure_count,can_replay,did_execute,wait_until,details,created,finished FROM engine_status"
+	var cases = []struct {
+		name   string
+		filter []*v1.FilterExpression
+		order  []*v1.OrderExpression
+		start  int
+		limit  int
+		sql    string
+		args   []interface{}
+	}{
+		{
+			name: "no filter",
+			sql:  cols + " ORDER BY created ASC, name ASC LIMIT 100",
+		},
+		{
+			name:  "paging",
+			start: 20, limit: 10,
+			sql: cols + " ORDER BY created ASC, name ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:  "paging without limit",
+			start: 20,
+			sql:   cols + " ORDER BY created ASC, name ASC LIMIT 80 OFFSET 20",
+		},
+		{
+			name: "equals and starts with",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{{Field: "phase", Value: "running"}, {Field: "phase", Value: "waiting"}}},
+				{Terms: []*v1.FilterTerm{{Field: "repo.ref", Value: "release_", Operation: v1.FilterOp_OP_STARTS_WITH}}},
+			},
+			sql:  cols + " WHERE (phase=$1 OR phase=$2) AND repo_ref LIKE $3 ORDER BY created ASC, name ASC LIMIT 100",
+			args: []interface{}{"running", "waiting", `release\_%`},
+		},
+		{
+			name: "negated contains and success",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{{Field: "name", Value: "100%", Operation: v1.FilterOp_OP_CONTAINS, Negate: true}}},
+				{Terms: []*v1.FilterTerm{{Field: "success", Value: "false"}}},
+			},
+			sql:  cols + " WHERE NOT name LIKE $1 AND success=$2 ORDER BY created ASC, name ASC LIMIT 100",
+			args: []interface{}{`%100\%%`, false},
+		},
+		{
+			name: "exists",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{{Field: "repo.host", Operation: v1.FilterOp_OP_EXISTS}, {Field: "owner", Operation: v1.FilterOp_OP_EXISTS}}},
+			},
+			sql:  cols + " WHERE (repo_host<>$1 OR owner<>$2) ORDER BY created ASC, name ASC LIMIT 100",
+			args: []interface{}{"", ""},
+		},
+		{
+			name: "annotations",
+			filter: []*v1.FilterExpression{
+				{Terms: []*v1.FilterTerm{{Field: "annotation.ticket", Value: "OPS-", Operation: v1.FilterOp_OP_STARTS_WITH}}},
+				{Terms: []*v1.FilterTerm{{Field: "annotation.skip", Operation: v1.FilterOp_OP_EXISTS, Negate: true}}},
+			},
+			sql:  cols + " WHERE name IN (SELECT engine_name FROM engine_annotation WHERE key=$1 AND value LIKE $2) AND NOT name IN (SELECT engine_name FROM engine_annotation WHERE key=$3) ORDER BY created ASC, name ASC LIMIT 100",
+			args: []interface{}{"ticket", "OPS-%", "skip"},
+		},
+		{
+			name:  "order",
+			order: []*v1.OrderExpression{{Field: "finished"}, {Field: "owner", Ascending: true}},
+			limit: 5,
+			sql:   cols + " ORDER BY finished DESC, owner ASC, name ASC LIMIT 5",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := findQuery(c.filter, c.order, c.start, c.limit, 100)
+			assert.NoError(t, err)
+			sql, args, err := q.ToSQL()
+			assert.NoError(t, err)
+			assert.EqualValues(t, c.sql, sql)
+			assert.EqualValues(t, c.args, args)
+		})
+	}
+}
+
+func TestFindQuery_Whitelist(t *testing.T) {
+	var cases = []struct {
+		name   string
+		filter []*v1.FilterExpression
+		order  []*v1.OrderExpression
+	}{
+		{"unknown filter field", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "name=name OR 1", Value: "1"}}}}, nil},
+		{"empty annotation", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "annotation.", Value: "1"}}}}, nil},
+		{"unknown order field", nil, []*v1.OrderExpression{{Field: "created; DROP TABLE engine_status"}}},
+		{"annotation order", nil, []*v1.OrderExpression{{Field: "annotation.ticket"}}},
+		{"success like", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "success", Value: "t", Operation: v1.FilterOp_OP_STARTS_WITH}}}}, nil},
+		{"success exists", []*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "success", Operation: v1.FilterOp_OP_EXISTS}}}}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := findQuery(c.filter, c.order, 0, 0, 100)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCountQuery(t *testing.T) {
+	q, err := countQuery([]*v1.FilterExpression{{Terms: []*v1.FilterTerm{{Field: "owner", Value: "dba"}}}})
+	assert.NoError(t, err)
+	sql, args, err := q.ToSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT COUNT(*) FROM engine_status WHERE owner=$1", sql)
+	assert.EqualValues(t, []interface{}{"dba"}, args)
+}
@@ -0,0 +1,5 @@
+CREATE TABLE engine_spec (
+    engine_name varchar(255) NOT NULL PRIMARY KEY,
+    engine_yaml bytea        NOT NULL,
+    sideload    bytea        NULL
+);
@@ -0,0 +1,59 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bhojpur/sql/pkg/store"
+)
+
+// NumberGroup provides atomic number groups backed by a PostgreSQL database
+type NumberGroup struct {
+	DB *sql.DB
+}
+
+var _ store.NumberGroup = &NumberGroup{}
+
+// NewNumberGroup creates a new PostgreSQL number group. The database schema must
+// have been brought up to date using Migrate.
+func NewNumberGroup(db *sql.DB) *NumberGroup {
+	return &NumberGroup{DB: db}
+}
+
+// Latest returns the latest number of a group
+func (ng *NumberGroup) Latest(ctx context.Context, group string) (nr int, err error) {
+	err = ng.DB.QueryRowContext(ctx, "SELECT val FROM number_group WHERE name = $1", group).Scan(&nr)
+	if err == sql.ErrNoRows {
+		return 0, store.ErrNotFound
+	}
+	return nr, err
+}
+
+// Next returns the next number of a group
+func (ng *NumberGroup) Next(ctx context.Context, group string) (nr int, err error) {
+	err = ng.DB.QueryRowContext(ctx, `
+		INSERT INTO number_group (name, val) VALUES ($1, 1)
+		ON CONFLICT (name) DO UPDATE SET val = number_group.val + 1
+		RETURNING val`, group).Scan(&nr)
+	return nr, err
+}
@@ -0,0 +1,164 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+	"github.com/bhojpur/sql/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	_ "github.com/lib/pq"
+)
+
+// openTestDB connects to the database named by SQL_TEST_DB. The database
+// must be disposable: all tables are dropped before each test.
+func openTestDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("SQL_TEST_DB")
+	if dsn == "" {
+		t.Skip("SQL_TEST_DB is not set - skipping PostgreSQL tests")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec("DROP TABLE IF EXISTS engine_annotation, engine_result, engine_status, engine_spec, number_group, schema_migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := Migrate(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, applied)
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := openTestDB(t)
+	applied, err := Migrate(context.Background(), db)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestEngineStore(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	s := NewEngineStore(db)
+
+	created := time.Date(2021, 12, 24, 18, 0, 0, 0, time.UTC)
+	status := &v1.EngineStatus{
+		Name:  "migrate.1",
+		Phase: v1.EnginePhase_PHASE_DONE,
+		Metadata: &v1.EngineMetadata{
+			Owner:          "dba",
+			Repository:     &v1.Repository{Host: "github.com", Owner: "bhojpur", Repo: "sql", Ref: "main", Revision: "abc"},
+			Trigger:        v1.EngineTrigger_TRIGGER_PUSH,
+			Created:        timestamppb.New(created),
+			Finished:       timestamppb.New(created.Add(time.Minute)),
+			Annotations:    []*v1.Annotation{{Key: "ticket", Value: "OPS-42"}, {Key: "reviewer", Value: "ops"}},
+			EngineSpecName: "migrate",
+		},
+		Conditions: &v1.EngineConditions{Success: true, DidExecute: true, CanReplay: true},
+		Details:    "all good",
+		Results:    []*v1.EngineResult{{Type: "url", Payload: "https://example.com", Description: "report", Channels: []string{"github"}}},
+	}
+	assert.NoError(t, s.Store(ctx, status))
+	assert.NoError(t, s.Store(ctx, status))
+
+	res, err := s.Get(ctx, "migrate.1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(status, res), "stored %v, got %v", status, res)
+	_, err = s.Get(ctx, "migrate.2")
+	assert.EqualValues(t, store.ErrNotFound, err)
+
+	for i := 2; i <= 4; i++ {
+		assert.NoError(t, s.Store(ctx, &v1.EngineStatus{
+			Name:     "migrate." + string(rune('0'+i)),
+			Phase:    v1.EnginePhase_PHASE_RUNNING,
+			Metadata: &v1.EngineMetadata{Created: timestamppb.New(created.Add(time.Duration(i) * time.Hour))},
+		}))
+	}
+	slice, total, err := s.Find(ctx, nil, nil, 1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, total)
+	if assert.Len(t, slice, 2) {
+		assert.EqualValues(t, "migrate.2", slice[0].Name)
+		assert.EqualValues(t, "migrate.3", slice[1].Name)
+	}
+
+	slice, total, err = s.Find(ctx, []*v1.FilterExpression{
+		{Terms: []*v1.FilterTerm{{Field: "phase", Value: "running"}}},
+	}, []*v1.OrderExpression{{Field: "created"}}, 0, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	if assert.Len(t, slice, 2) {
+		assert.EqualValues(t, "migrate.4", slice[0].Name)
+		assert.EqualValues(t, "migrate.3", slice[1].Name)
+	}
+
+	slice, total, err = s.Find(ctx, []*v1.FilterExpression{
+		{Terms: []*v1.FilterTerm{{Field: "annotation.ticket", Value: "OPS-", Operation: v1.FilterOp_OP_STARTS_WITH}}},
+	}, nil, 0, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	if assert.Len(t, slice, 1) {
+		assert.EqualValues(t, "migrate.1", slice[0].Name)
+	}
+}
+
+func TestNumberGroup(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	ng := NewNumberGroup(db)
+	_, err := ng.Latest(ctx, "migrate")
+	assert.EqualValues(t, store.ErrNotFound, err)
+	for i := 1; i <= 3; i++ {
+		nr, err := ng.Next(ctx, "migrate")
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, nr)
+	}
+	nr, err := ng.Latest(ctx, "migrate")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, nr)
+}
+
+func TestEngineSpecStore(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	specs := NewEngineSpecStore(db)
+	_, err := specs.Get(ctx, "migrate.1")
+	assert.EqualValues(t, store.ErrNotFound, err)
+
+	assert.NoError(t, specs.Store(ctx, "migrate.1", &store.EngineSpec{EngineYAML: []byte("driver: postgres")}))
+	assert.NoError(t, specs.Store(ctx, "migrate.1", &store.EngineSpec{EngineYAML: []byte("driver: mysql"), Sideload: []byte{1, 2}}))
+	spec, err := specs.Get(ctx, "migrate.1")
+	assert.NoError(t, err)
+	assert.Equal(t, &store.EngineSpec{EngineYAML: []byte("driver: mysql"), Sideload: []byte{1, 2}}, spec)
+}
@@ -0,0 +1,64 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bhojpur/sql/pkg/store"
+)
+
+// EngineSpecStore stores engine specs in a PostgreSQL database
+type EngineSpecStore struct {
+	DB *sql.DB
+}
+
+var _ store.EngineSpecs = &EngineSpecStore{}
+
+// NewEngineSpecStore creates a new PostgreSQL engine spec store. The database schema must
+// have been brought up to date using Migrate.
+func NewEngineSpecStore(db *sql.DB) *EngineSpecStore {
+	return &EngineSpecStore{DB: db}
+}
+
+// Store stores the spec of an engine
+func (s *EngineSpecStore) Store(ctx context.Context, name string, spec *store.EngineSpec) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO engine_spec (engine_name, engine_yaml, sideload) VALUES ($1, $2, $3)
+		ON CONFLICT (engine_name) DO UPDATE SET engine_yaml = EXCLUDED.engine_yaml, sideload = EXCLUDED.sideload`,
+		name, spec.EngineYAML, spec.Sideload)
+	return err
+}
+
+// Get retrieves the spec of an engine
+func (s *EngineSpecStore) Get(ctx context.Context, name string) (*store.EngineSpec, error) {
+	var spec store.EngineSpec
+	err := s.DB.QueryRowContext(ctx, "SELECT engine_yaml, sideload FROM engine_spec WHERE engine_name = $1", name).
+		Scan(&spec.EngineYAML, &spec.Sideload)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
@@ -0,0 +1,71 @@
+package store
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/bhojpur/sql/pkg/api/v1"
+)
+
+var (
+	// ErrNotFound is returned by Get if the engine or its spec does not exist
+	ErrNotFound = errors.New("not found")
+)
+
+// Engines stores the status of engines
+type Engines interface {
+	// Store stores the engine status. An existing status of an engine with the same name is replaced.
+	Store(ctx context.Context, status *v1.EngineStatus) error
+
+	// Get retrieves a particular engine by its name. If the engine does not exist, ErrNotFound is returned.
+	Get(ctx context.Context, name string) (*v1.EngineStatus, error)
+
+	// Find searches for engines matching the filter and returns the slice denoted by start and limit,
+	// as well as the total number of matching engines. A limit of zero means no limit.
+	Find(ctx context.Context, filter []*v1.FilterExpression, order []*v1.OrderExpression, start, limit int) (slice []*v1.EngineStatus, total int, err error)
+}
+
+// NumberGroup enables simple atomic number incrementing, e.g. to number engines
+type NumberGroup interface {
+	// Latest returns the latest number of a group. If the group has never been used, ErrNotFound is returned.
+	Latest(ctx context.Context, group string) (nr int, err error)
+
+	// Next returns the next number of a group. The first number of a group is 1.
+	Next(ctx context.Context, group string) (nr int, err error)
+}
+
+// EngineSpec is everything needed to run an engine: its engine YAML and the
+// gzipped tar its workspace is populated with
+type EngineSpec struct {
+	EngineYAML []byte
+	Sideload   []byte
+}
+
+// EngineSpecs stores the specs engines were started with
+type EngineSpecs interface {
+	// Store stores the spec of an engine. An existing spec of an engine with the same name is replaced.
+	Store(ctx context.Context, name string, spec *EngineSpec) error
+
+	// Get retrieves the spec of an engine. If there is none, ErrNotFound is returned.
+	Get(ctx context.Context, name string) (*EngineSpec, error)
+}